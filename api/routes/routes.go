@@ -6,17 +6,20 @@ import (
 	"github.com/PythonHacker24/linux-acl-management-backend/api/middleware"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/auth"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/health"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/search"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/traversal"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
 )
 
 /* all routes for all features are registered here */
-func RegisterRoutes(mux *http.ServeMux, sessionManager *session.Manager) {
+func RegisterRoutes(mux *http.ServeMux, sessionManager *session.Manager, permProcessor *transprocessor.PermProcessor, validator *validation.Validator, watchdog *scheduler.Watchdog) {
 
 	/* move it to config file */
 	allowedOrigin := []string{"http://localhost:3000"}
-	allowedMethods := []string{"GET", "POST", "OPTIONS"}
+	allowedMethods := []string{"GET", "POST", "DELETE", "OPTIONS"}
 	allowedHeaders := []string{"*"}
 
 	/* for monitoring the state of overall server and laclm backend */
@@ -179,6 +182,288 @@ func RegisterRoutes(mux *http.ServeMux, sessionManager *session.Manager) {
 		),
 	)
 
+	/* for scheduling changes of several paths as one unit */
+	mux.Handle("POST /transactions/changeset", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.IssueChangeset),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/changeset */
+	mux.HandleFunc("OPTIONS /transactions/changeset",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for previewing the outcome of a transaction without scheduling it */
+	mux.Handle("POST /transactions/preview", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(transprocessor.PreviewTransactionHandler(validator, permProcessor)),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/preview */
+	mux.HandleFunc("OPTIONS /transactions/preview",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for reverting a processed transaction to the ACL it replaced */
+	mux.Handle("POST /transactions/{id}/revert", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(transprocessor.RevertTransactionHandler(sessionManager, permProcessor)),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/{id}/revert */
+	mux.HandleFunc("OPTIONS /transactions/{id}/revert",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for listing deferred transactions that are still waiting */
+	mux.Handle("GET /transactions/deferred", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.ListDeferredTransactionsHandler),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/deferred */
+	mux.HandleFunc("OPTIONS /transactions/deferred",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for listing transactions left in the pending archive by expired sessions */
+	mux.Handle("GET /transactions/recoverable", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.ListRecoverableTransactionsHandler),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/recoverable */
+	mux.HandleFunc("OPTIONS /transactions/recoverable",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for queueing a transaction from the pending archive again */
+	mux.Handle("POST /transactions/{id}/replay", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.ReplayTransaction),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/{id}/replay */
+	mux.HandleFunc("OPTIONS /transactions/{id}/replay",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for looking up a transaction by ID in Redis and the archive */
+	mux.Handle("GET /transactions/{id}", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.GetTransaction),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* for cancelling a transaction that is still deferred or queued */
+	mux.Handle("DELETE /transactions/{id}", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.CancelTransaction),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /transactions/{id} */
+	mux.HandleFunc("OPTIONS /transactions/{id}",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for listing active time-bound grants */
+	mux.Handle("GET /grants", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.ListGrantsHandler),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /grants */
+	mux.HandleFunc("OPTIONS /grants",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for moving the expiry of a time-bound grant */
+	mux.Handle("POST /grants/{id}/extend", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.ExtendGrantHandler),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /grants/{id}/extend */
+	mux.HandleFunc("OPTIONS /grants/{id}/extend",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
+	/* for revoking a time-bound grant before it expires */
+	mux.Handle("POST /grants/{id}/revoke", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(sessionManager.RevokeGrantHandler),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /grants/{id}/revoke */
+	mux.HandleFunc("OPTIONS /grants/{id}/revoke",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
+
 	/*
 		for fetching list of users matching the query in the LDAP server
 		supports URL params: q (Query)
@@ -322,4 +607,33 @@ func RegisterRoutes(mux *http.ServeMux, sessionManager *session.Manager) {
 			allowedHeaders,
 		),
 	)
+
+	/* for admins to tell whether the scheduler is stuck (queues, workers, in-flight and oldest pending transactions) */
+	mux.Handle("GET /admin/scheduler", http.HandlerFunc(
+		middleware.CORSMiddleware(
+			middleware.LoggingMiddleware(
+				middleware.AuthenticationMiddleware(
+					scheduler.IntrospectionHandler(watchdog, sessionManager, search.LDAPDirectory{}),
+				),
+			),
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	))
+
+	/* handle OPTIONS preflight requests for /admin/scheduler */
+	mux.HandleFunc("OPTIONS /admin/scheduler",
+		middleware.CORSMiddleware(
+			func(w http.ResponseWriter, r *http.Request) {
+				/*
+						This handler will never be called because CORSMiddleware handles OPTIONS
+					 	but we need it for the route to be registered
+				*/
+			},
+			allowedOrigin,
+			allowedMethods,
+			allowedHeaders,
+		),
+	)
 }
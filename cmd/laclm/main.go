@@ -27,9 +27,12 @@ import (
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/redis"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler/fcfs"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler/wfq"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/search"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/utils"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
 )
 
 func main() {
@@ -191,12 +194,25 @@ func run(ctx context.Context) error {
 
 	archivalPQ := postgresql.New(poolPQ)
 
-	/* create a session manager */
-	sessionManager := session.NewManager(logRedisClient, archivalPQ, errChLog)
-
 	/* create a permissions processor */
 	permProcessor := transprocessor.NewPermProcessor(pool, errChLog)
 
+	/* requests are validated against LDAP and the filesystem servers before they are queued */
+	validator := validation.NewValidator(permProcessor, search.LDAPDirectory{})
+
+	/* create a session manager */
+	sessionManager := session.NewManager(logRedisClient, archivalPQ, validator, pool, errChLog)
+
+	/* transactions parked on a daemon with an open circuit are dispatched once it recovers */
+	pool.OnCircuitClose(sessionManager.ServerRecovered)
+
+	/* take the lease on the durable queue before anything is queued */
+	if err := sessionManager.Heartbeat(ctx); err != nil {
+		zap.L().Error("Failed to take the queue lease",
+			zap.Error(err),
+		)
+	}
+
 	/* start logging goroutine - should be last to exit */
 	logWg.Add(1)
 	go func(ctx context.Context) {
@@ -222,17 +238,82 @@ func run(ctx context.Context) error {
 		}
 	}(logCtx)
 
-	/* currently FCFS scheduler */
-	transSched := fcfs.NewFCFSScheduler(sessionManager, permProcessor)
+	/* tracks transactions handed to workers, shared with the scheduler introspection endpoint */
+	watchdog := scheduler.NewWatchdog(scheduler.MaxWorkers())
+
+	/* scheduler is selected with app.scheduler (fcfs by default) */
+	var transSched scheduler.Scheduler
+	switch config.BackendConfig.AppInfo.Scheduler {
+	case config.SchedulerWFQ:
+		transSched = wfq.NewWFQScheduler(sessionManager, permProcessor, search.LDAPDirectory{}, watchdog)
+	default:
+		transSched = fcfs.NewFCFSScheduler(sessionManager, permProcessor, watchdog)
+	}
 
 	/* initialize the scheduler */
 	scheduler.InitScheduler(ctx, transSched, &wg, errChShed)
 
+	/* flag or cancel transactions running past the watchdog deadline */
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		watchdog.Run(ctx,
+			time.Duration(config.BackendConfig.Watchdog.CheckInterval)*time.Second,
+			time.Duration(config.BackendConfig.Watchdog.Deadline)*time.Second,
+			config.BackendConfig.Watchdog.Action == config.WatchdogActionCancel,
+		)
+	}(ctx)
+
+	/* dispatch deferred transactions into session queues when they are due */
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		sessionManager.RunDeferredDispatcher(ctx,
+			time.Duration(config.BackendConfig.AppInfo.DeferredPollInterval)*time.Second,
+		)
+	}(ctx)
+
+	/* revoke expired time-bound grants and notify users before they expire */
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		sessionManager.RunGrantReaper(ctx,
+			time.Duration(config.BackendConfig.Grants.ReaperInterval)*time.Second,
+			time.Duration(config.BackendConfig.Grants.NoticeBefore)*time.Hour,
+		)
+	}(ctx)
+
+	queueLease := time.Duration(config.BackendConfig.Queue.LeaseDuration) * time.Second
+
+	/* queue transactions a crash or kill left unacknowledged in the durable queue */
+	if err := sessionManager.RecoverQueuedTransactions(ctx, queueLease); err != nil {
+		zap.L().Error("Failed to recover queued transactions",
+			zap.Error(err),
+		)
+	}
+
+	/* keep the queue lease and reclaim transactions of instances whose lease expired since */
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		sessionManager.RunQueueLease(ctx,
+			time.Duration(config.BackendConfig.Queue.HeartbeatInterval)*time.Second,
+			queueLease,
+		)
+	}(ctx)
+
+	/* queue transactions left in the pending archive by expired sessions or the last shutdown */
+	if err := sessionManager.ReplayPendingTransactionsOnStartup(); err != nil {
+		zap.L().Error("Failed to replay pending transactions",
+			zap.Error(err),
+		)
+	}
+
 	/* setting up http mux and routes */
 	mux := http.NewServeMux()
 
 	/* routes declared in /api/routes.go */
-	routes.RegisterRoutes(mux, sessionManager)
+	routes.RegisterRoutes(mux, sessionManager, permProcessor, validator, watchdog)
 
 	/* create a http server */
 	server := &http.Server{
@@ -314,16 +395,21 @@ func run(ctx context.Context) error {
 
 	wg.Wait()
 
-	/* close connections with daemon */
-	pool.CloseAll(errChLog)
-
-	/* flush Redis data before closing */
-	if err := logRedisClient.FlushAll(context.Background()); err != nil {
-		zap.L().Error("Failed to flush Redis data during shutdown",
+	/* transactions this instance left in the durable queue can be reclaimed without waiting for the lease */
+	if err := sessionManager.ReleaseInstance(context.Background()); err != nil {
+		zap.L().Error("Failed to release the queue lease",
 			zap.Error(err),
 		)
 	}
 
+	/* close connections with daemon */
+	pool.CloseAll(errChLog)
+
+	/*
+		Redis isn't flushed, expired sessions removed their own keys
+		and idempotency keys have to outlive a restart
+	*/
+
 	/* close archival database connection */
 	poolPQ.Close()
 
@@ -6,14 +6,23 @@ import (
 	"github.com/MakeNowJust/heredoc"
 )
 
+/* schedulers available for dispatching transactions */
+const (
+	SchedulerFCFS = "fcfs"
+	SchedulerWFQ  = "wfq"
+)
+
 /* app parameters */
 type App struct {
-	Name           string `yaml:"name,omitempty"`
-	Version        string `yaml:"version,omitempty"`
-	DebugMode      bool   `yaml:"debug_mode,omitempty"`
-	SessionTimeout int    `yaml:"session_timeout,omitempty"`
-	BasePath       string `yaml:"base_path,omitempty"`
-	MaxWorkers     int    `yaml:"max_workers,omitempty"`
+	Name                 string `yaml:"name,omitempty"`
+	Version              string `yaml:"version,omitempty"`
+	DebugMode            bool   `yaml:"debug_mode,omitempty"`
+	SessionTimeout       int    `yaml:"session_timeout,omitempty"`
+	BasePath             string `yaml:"base_path,omitempty"`
+	MaxWorkers           int    `yaml:"max_workers,omitempty"`
+	IdempotencyTTL       int    `yaml:"idempotency_ttl,omitempty"`
+	DeferredPollInterval int    `yaml:"deferred_poll_interval,omitempty"`
+	Scheduler            string `yaml:"scheduler,omitempty"`
 }
 
 /* normalization function */
@@ -50,5 +59,29 @@ func (a *App) Normalize() error {
 
 	/* max_workers can be zero - it will be adjusted scheduler */
 
+	/* set default idempotency key retention to 24 hours */
+	if a.IdempotencyTTL == 0 {
+		a.IdempotencyTTL = 24
+	}
+
+	/* set default deferred transaction poll interval to 10 seconds */
+	if a.DeferredPollInterval == 0 {
+		a.DeferredPollInterval = 10
+	}
+
+	/* set default scheduler to fcfs */
+	if a.Scheduler == "" {
+		a.Scheduler = SchedulerFCFS
+	}
+
+	if a.Scheduler != SchedulerFCFS && a.Scheduler != SchedulerWFQ {
+		return errors.New(heredoc.Doc(`
+			Invalid scheduler in the configuration file. 
+			scheduler must be fcfs or wfq.
+
+			Please check the docs for more information: 
+		`))
+	}
+
 	return nil
 }
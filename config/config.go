@@ -14,6 +14,13 @@ type Config struct {
 	FileSystemServers []FileSystemServers `yaml:"filesystem_servers,omitempty"`
 	BackendSecurity   BackendSecurity     `yaml:"backend_security,omitempty"`
 	Authentication    Authentication      `yaml:"authentication,omitempty"`
+	Retry             Retry               `yaml:"retry,omitempty"`
+	Grants            Grants              `yaml:"grants,omitempty"`
+	Queue             Queue               `yaml:"queue,omitempty"`
+	Replay            Replay              `yaml:"replay,omitempty"`
+	FairQueuing       FairQueuing         `yaml:"fair_queuing,omitempty"`
+	Priority          Priority            `yaml:"priority,omitempty"`
+	Watchdog          Watchdog            `yaml:"watchdog,omitempty"`
 }
 
 /* complete config normalizer function */
@@ -48,5 +55,33 @@ func (c *Config) Normalize() error {
 		return fmt.Errorf("authentication configuration error: %w", err)
 	}
 
+	if err := c.Retry.Normalize(); err != nil {
+		return fmt.Errorf("retry configuration error: %w", err)
+	}
+
+	if err := c.Grants.Normalize(); err != nil {
+		return fmt.Errorf("grants configuration error: %w", err)
+	}
+
+	if err := c.Queue.Normalize(); err != nil {
+		return fmt.Errorf("queue configuration error: %w", err)
+	}
+
+	if err := c.Replay.Normalize(); err != nil {
+		return fmt.Errorf("replay configuration error: %w", err)
+	}
+
+	if err := c.FairQueuing.Normalize(); err != nil {
+		return fmt.Errorf("fair queuing configuration error: %w", err)
+	}
+
+	if err := c.Priority.Normalize(); err != nil {
+		return fmt.Errorf("priority configuration error: %w", err)
+	}
+
+	if err := c.Watchdog.Normalize(); err != nil {
+		return fmt.Errorf("watchdog configuration error: %w", err)
+	}
+
 	return nil
 }
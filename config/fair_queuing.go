@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* weights of the weighted fair queuing scheduler, a higher weight gets more worker slots */
+type FairQueuing struct {
+	DefaultWeight int            `yaml:"default_weight,omitempty"`
+	Users         map[string]int `yaml:"users,omitempty"`
+	Groups        map[string]int `yaml:"groups,omitempty"`
+}
+
+/* normalization function */
+func (f *FairQueuing) Normalize() error {
+
+	/* set default weight to 1 */
+	if f.DefaultWeight == 0 {
+		f.DefaultWeight = 1
+	}
+
+	valid := f.DefaultWeight > 0
+	for _, weight := range f.Users {
+		valid = valid && weight > 0
+	}
+	for _, weight := range f.Groups {
+		valid = valid && weight > 0
+	}
+
+	if !valid {
+		return errors.New(heredoc.Doc(`
+			Invalid fair queuing weights in the configuration file. 
+			default_weight and the weights of users and groups must be positive.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	return nil
+}
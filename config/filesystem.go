@@ -8,15 +8,17 @@ import (
 
 /* file system server parameters */
 type FileSystemServers struct {
-	Path   string  `yaml:"path,omitempty"`
-	Method string  `yaml:"method,omitempty"`
-	Remote *Remote `yaml:"remote,omitempty"`
+	Path           string  `yaml:"path,omitempty"`
+	Method         string  `yaml:"method,omitempty"`
+	Remote         *Remote `yaml:"remote,omitempty"`
+	MaxConcurrency int     `yaml:"max_concurrency,omitempty"`
 }
 
 /* remote parameters for file system server with laclm daemons installed */
 type Remote struct {
-	Host string `yaml:"host,omitempty"`
-	Port int    `yaml:"port,omitempty"`
+	Host             string `yaml:"host,omitempty"`
+	Port             int    `yaml:"port,omitempty"`
+	FailureThreshold int    `yaml:"failure_threshold,omitempty"`
 }
 
 /* normalization function */
@@ -29,6 +31,16 @@ func (f *FileSystemServers) Normalize() error {
 		`))
 	}
 
+	/* max_concurrency 0 means the server is only limited by max_workers */
+	if f.MaxConcurrency < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid max_concurrency for file system server in the configuration file. 
+			max_concurrency must be positive (or 0 for no limit).
+
+			Please check the docs for more information: 
+		`))
+	}
+
 	/* set default method to local */
 	if f.Method == "" {
 		f.Method = "local"
@@ -62,6 +74,19 @@ func (f *FileSystemServers) Normalize() error {
 				Please check the docs for more information: 
 			`))
 		}
+
+		/* set default failures opening the circuit breaker of the daemon to 5 */
+		if f.Remote.FailureThreshold == 0 {
+			f.Remote.FailureThreshold = 5
+		}
+
+		if f.Remote.FailureThreshold < 0 {
+			return errors.New(heredoc.Doc(`
+				Invalid failure_threshold for remote file server 
+
+				Please check the docs for more information: 
+			`))
+		}
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* time-bound grant parameters */
+type Grants struct {
+	ReaperInterval int `yaml:"reaper_interval,omitempty"`
+	NoticeBefore   int `yaml:"notice_before,omitempty"`
+}
+
+/* normalization function */
+func (g *Grants) Normalize() error {
+
+	/* set default reaper interval to 60 seconds */
+	if g.ReaperInterval == 0 {
+		g.ReaperInterval = 60
+	}
+
+	/* set default expiry notice to 24 hours before a grant expires */
+	if g.NoticeBefore == 0 {
+		g.NoticeBefore = 24
+	}
+
+	if g.ReaperInterval < 0 || g.NoticeBefore < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid grants parameters in the configuration file. 
+			reaper_interval and notice_before must be positive.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* priority lanes of transactions, the roles are LDAP groups allowed to use the restricted lanes */
+type Priority struct {
+	AgingInterval int      `yaml:"aging_interval,omitempty"`
+	HighRoles     []string `yaml:"high_roles,omitempty"`
+	UrgentRoles   []string `yaml:"urgent_roles,omitempty"`
+}
+
+/* normalization function */
+func (p *Priority) Normalize() error {
+
+	/* set default aging interval to 5 minutes, a waiting transaction moves up a lane every interval */
+	if p.AgingInterval == 0 {
+		p.AgingInterval = 300
+	}
+
+	if p.AgingInterval < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid priority parameters in the configuration file. 
+			aging_interval must be positive.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	/* without roles nobody can use high or urgent */
+
+	return nil
+}
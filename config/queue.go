@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* durable queue parameters, an instance holds a lease on its queued transactions while it heartbeats */
+type Queue struct {
+	HeartbeatInterval int `yaml:"heartbeat_interval,omitempty"`
+	LeaseDuration     int `yaml:"lease_duration,omitempty"`
+}
+
+/* normalization function */
+func (q *Queue) Normalize() error {
+
+	/* set default heartbeat interval to 15 seconds */
+	if q.HeartbeatInterval == 0 {
+		q.HeartbeatInterval = 15
+	}
+
+	/* set default lease duration to 60 seconds, a few missed heartbeats before transactions are reclaimed */
+	if q.LeaseDuration == 0 {
+		q.LeaseDuration = 60
+	}
+
+	if q.HeartbeatInterval < 0 || q.LeaseDuration < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid queue parameters in the configuration file. 
+			heartbeat_interval and lease_duration must be positive.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	/* a lease shorter than the heartbeat would expire while the instance is alive */
+	if q.LeaseDuration <= q.HeartbeatInterval {
+		return errors.New(heredoc.Doc(`
+			Invalid queue parameters in the configuration file. 
+			lease_duration must be longer than heartbeat_interval.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* what happens to transactions left in the pending archive by expired sessions or a shutdown */
+const (
+	/* queued again into the next session of the user */
+	ReplayEnqueue = "enqueue"
+
+	/* listed to the user, who decides to replay or discard each one */
+	ReplayOffer = "offer"
+
+	/* kept in the archive only */
+	ReplayOff = "off"
+)
+
+/* pending transaction replay parameters */
+type Replay struct {
+	Mode string `yaml:"mode,omitempty"`
+
+	/* enqueue mode only: replay for every user on startup instead of waiting for them to log in */
+	OnStartup bool `yaml:"on_startup,omitempty"`
+}
+
+/* normalization function */
+func (r *Replay) Normalize() error {
+
+	/* set default mode to offer, nothing is executed without the user asking for it */
+	if r.Mode == "" {
+		r.Mode = ReplayOffer
+	}
+
+	if r.Mode != ReplayEnqueue && r.Mode != ReplayOffer && r.Mode != ReplayOff {
+		return errors.New(heredoc.Doc(`
+			Invalid replay mode in the configuration file. 
+			mode must be one of enqueue, offer or off.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/*
+retry parameters for transactions failing with transient errors
+requests to daemons running past their timeout fail transiently as well
+*/
+type Retry struct {
+	MaxAttempts    int     `yaml:"max_attempts,omitempty"`
+	InitialBackoff int     `yaml:"initial_backoff,omitempty"`
+	MaxBackoff     int     `yaml:"max_backoff,omitempty"`
+	Multiplier     float64 `yaml:"multiplier,omitempty"`
+	ApplyTimeout   int     `yaml:"apply_timeout,omitempty"`
+	RequestTimeout int     `yaml:"request_timeout,omitempty"`
+}
+
+/* normalization function */
+func (r *Retry) Normalize() error {
+
+	/* set default max attempts to 3 (1 disables retries) */
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 3
+	}
+
+	/* set default initial backoff to 2 seconds */
+	if r.InitialBackoff == 0 {
+		r.InitialBackoff = 2
+	}
+
+	/* set default max backoff to 60 seconds */
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = 60
+	}
+
+	/* set default backoff multiplier to 2 */
+	if r.Multiplier == 0 {
+		r.Multiplier = 2
+	}
+
+	/* set default timeout of ACL changes sent to daemons (recursive ones walk whole trees) to 600 seconds */
+	if r.ApplyTimeout == 0 {
+		r.ApplyTimeout = 600
+	}
+
+	/* set default timeout of ACL reads and restores sent to daemons to 60 seconds */
+	if r.RequestTimeout == 0 {
+		r.RequestTimeout = 60
+	}
+
+	if r.MaxAttempts < 0 || r.InitialBackoff < 0 || r.MaxBackoff < 0 || r.Multiplier < 1 || r.ApplyTimeout < 0 || r.RequestTimeout < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid retry parameters in the configuration file. 
+			max_attempts, initial_backoff, max_backoff, apply_timeout and request_timeout must be positive and multiplier at least 1.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	if r.MaxBackoff < r.InitialBackoff {
+		r.MaxBackoff = r.InitialBackoff
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+/* actions taken on a transaction running past the watchdog deadline */
+const (
+	WatchdogActionFlag   = "flag"
+	WatchdogActionCancel = "cancel"
+)
+
+/* scheduler watchdog, the admin roles are LDAP groups allowed to inspect the scheduler */
+type Watchdog struct {
+	Deadline      int      `yaml:"deadline,omitempty"`
+	Action        string   `yaml:"action,omitempty"`
+	CheckInterval int      `yaml:"check_interval,omitempty"`
+	AdminRoles    []string `yaml:"admin_roles,omitempty"`
+}
+
+/* normalization function */
+func (w *Watchdog) Normalize() error {
+
+	/* set default deadline to 15 minutes, a little over the timeout of a daemon call */
+	if w.Deadline == 0 {
+		w.Deadline = 900
+	}
+
+	/* set default check interval to 10 seconds */
+	if w.CheckInterval == 0 {
+		w.CheckInterval = 10
+	}
+
+	if w.Deadline < 0 || w.CheckInterval < 0 {
+		return errors.New(heredoc.Doc(`
+			Invalid watchdog parameters in the configuration file. 
+			deadline and check_interval must be positive.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	/* set default action to flag, overrunning transactions are only reported */
+	if w.Action == "" {
+		w.Action = WatchdogActionFlag
+	}
+
+	if w.Action != WatchdogActionFlag && w.Action != WatchdogActionCancel {
+		return errors.New(heredoc.Doc(`
+			Invalid watchdog action in the configuration file. 
+			action must be flag or cancel.
+
+			Please check the docs for more information: 
+		`))
+	}
+
+	/* without admin roles nobody can inspect the scheduler */
+
+	return nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS results_transactions_archive;
+DROP TABLE IF EXISTS pending_transactions_archive;
+DROP TABLE IF EXISTS sessions_archive;
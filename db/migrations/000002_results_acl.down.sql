@@ -0,0 +1 @@
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS acl;
@@ -0,0 +1,2 @@
+-- ACL read by getfacl transactions
+ALTER TABLE results_transactions_archive ADD COLUMN IF NOT EXISTS acl JSONB NOT NULL DEFAULT '[]'::jsonb;
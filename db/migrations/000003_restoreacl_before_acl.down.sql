@@ -0,0 +1,6 @@
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS before_acl;
+
+ALTER TABLE pending_transactions_archive DROP CONSTRAINT IF EXISTS pending_transactions_archive_operation_check;
+ALTER TABLE pending_transactions_archive ADD CONSTRAINT pending_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl'));
+ALTER TABLE results_transactions_archive DROP CONSTRAINT IF EXISTS results_transactions_archive_operation_check;
+ALTER TABLE results_transactions_archive ADD CONSTRAINT results_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl'));
@@ -0,0 +1,8 @@
+-- copyacl operation and its source path
+ALTER TABLE pending_transactions_archive DROP CONSTRAINT IF EXISTS pending_transactions_archive_operation_check;
+ALTER TABLE pending_transactions_archive ADD CONSTRAINT pending_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl'));
+ALTER TABLE results_transactions_archive DROP CONSTRAINT IF EXISTS results_transactions_archive_operation_check;
+ALTER TABLE results_transactions_archive ADD CONSTRAINT results_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl'));
+
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS source_path TEXT;
+ALTER TABLE results_transactions_archive ADD COLUMN IF NOT EXISTS source_path TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE results_transactions_archive DROP CONSTRAINT IF EXISTS results_transactions_archive_status_check;
+ALTER TABLE results_transactions_archive ADD CONSTRAINT results_transactions_archive_status_check CHECK (status IN ('success', 'failed'));
@@ -0,0 +1,3 @@
+-- cancelled transactions are archived with the results
+ALTER TABLE results_transactions_archive DROP CONSTRAINT IF EXISTS results_transactions_archive_status_check;
+ALTER TABLE results_transactions_archive ADD CONSTRAINT results_transactions_archive_status_check CHECK (status IN ('success', 'failed', 'cancelled'));
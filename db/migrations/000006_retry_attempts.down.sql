@@ -0,0 +1,4 @@
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS attempts;
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS last_error;
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS attempts;
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS last_error;
@@ -0,0 +1,5 @@
+-- attempts and last transient error of retried transactions
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE results_transactions_archive ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE results_transactions_archive ADD COLUMN IF NOT EXISTS last_error TEXT;
@@ -0,0 +1,10 @@
+-- transactions waiting for their execute-at time
+CREATE TABLE IF NOT EXISTS deferred_transactions (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    execute_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status TEXT CHECK (status IN ('scheduled', 'dispatched', 'cancelled')) NOT NULL DEFAULT 'scheduled',
+    transaction JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMP WITH TIME ZONE
+);
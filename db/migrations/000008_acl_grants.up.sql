@@ -0,0 +1,17 @@
+-- time-bound ACL grants and their revocation
+CREATE TABLE IF NOT EXISTS acl_grants (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    txn_id UUID NOT NULL,
+    target_path TEXT NOT NULL,
+    entity_type VARCHAR(10) NOT NULL CHECK (entity_type IN ('user', 'group')),
+    entity TEXT NOT NULL,
+    permissions VARCHAR(3) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    recursive BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status TEXT CHECK (status IN ('active', 'expired', 'revoked', 'superseded')) NOT NULL DEFAULT 'active',
+    revoke_txn_id UUID,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS steps;
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS steps;
+
+ALTER TABLE pending_transactions_archive DROP CONSTRAINT IF EXISTS pending_transactions_archive_operation_check;
+ALTER TABLE pending_transactions_archive ADD CONSTRAINT pending_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl'));
+ALTER TABLE results_transactions_archive DROP CONSTRAINT IF EXISTS results_transactions_archive_operation_check;
+ALTER TABLE results_transactions_archive ADD CONSTRAINT results_transactions_archive_operation_check CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl'));
@@ -0,0 +1,3 @@
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS recursive;
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS acl;
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS expected_acl;
@@ -0,0 +1,4 @@
+-- what a pending transaction needs to be replayed and checked for conflicts
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS recursive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS acl JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS expected_acl JSONB NOT NULL DEFAULT '[]'::jsonb;
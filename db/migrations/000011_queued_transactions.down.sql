@@ -0,0 +1 @@
+DROP TABLE IF EXISTS queued_transactions;
@@ -0,0 +1,10 @@
+-- durable transaction queue, reclaimed on startup
+CREATE TABLE IF NOT EXISTS queued_transactions (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    owner UUID NOT NULL,
+    status TEXT CHECK (status IN ('queued', 'processing')) NOT NULL DEFAULT 'queued',
+    transaction JSONB NOT NULL,
+    enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMP WITH TIME ZONE
+);
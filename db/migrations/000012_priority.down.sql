@@ -0,0 +1,2 @@
+ALTER TABLE pending_transactions_archive DROP COLUMN IF EXISTS priority;
+ALTER TABLE results_transactions_archive DROP COLUMN IF EXISTS priority;
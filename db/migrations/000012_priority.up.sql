@@ -0,0 +1,3 @@
+-- priority lane of transactions
+ALTER TABLE pending_transactions_archive ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent'));
+ALTER TABLE results_transactions_archive ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent'));
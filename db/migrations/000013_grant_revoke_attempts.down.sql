@@ -0,0 +1 @@
+ALTER TABLE acl_grants DROP COLUMN IF EXISTS revoke_attempts;
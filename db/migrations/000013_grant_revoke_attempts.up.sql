@@ -0,0 +1,2 @@
+-- failed revocations of a grant, they are retried with backoff
+ALTER TABLE acl_grants ADD COLUMN IF NOT EXISTS revoke_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- running backend instances, queued transactions of an instance without a recent heartbeat are reclaimed
+CREATE TABLE IF NOT EXISTS backend_instances (
+    id UUID PRIMARY KEY,
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,85 @@
+-- name: CreateGrantPQ :one
+INSERT INTO acl_grants (
+    id,
+    username,
+    txn_id,
+    target_path,
+    entity_type,
+    entity,
+    permissions,
+    is_default,
+    recursive,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetActiveGrantsByUserPQ :many
+SELECT * FROM acl_grants
+WHERE username = $1 AND status = 'active'
+ORDER BY expires_at ASC;
+
+-- name: SupersedeGrantsPQ :exec
+UPDATE acl_grants
+SET status = 'superseded'
+WHERE target_path = $1 AND entity_type = $2 AND entity = $3 AND is_default = $4 AND status = 'active';
+
+-- name: ClaimExpiredGrantsPQ :many
+UPDATE acl_grants
+SET status = 'expired'
+WHERE id IN (
+    SELECT id FROM acl_grants
+    WHERE status = 'active' AND expires_at <= NOW()
+    ORDER BY expires_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ClaimGrantsNearingExpiryPQ :many
+UPDATE acl_grants
+SET notified_at = NOW()
+WHERE id IN (
+    SELECT id FROM acl_grants
+    WHERE status = 'active' AND notified_at IS NULL AND expires_at <= $1
+    ORDER BY expires_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ExtendGrantPQ :one
+UPDATE acl_grants
+SET
+    expires_at = $3,
+    notified_at = NULL
+WHERE id = $1 AND username = $2 AND status = 'active'
+RETURNING *;
+
+-- name: RevokeGrantPQ :one
+UPDATE acl_grants
+SET status = 'revoked'
+WHERE id = $1 AND username = $2 AND status = 'active'
+RETURNING *;
+
+-- name: SetGrantRevokeTxnPQ :exec
+UPDATE acl_grants
+SET revoke_txn_id = $2
+WHERE id = $1;
+
+-- name: ReactivateGrantPQ :exec
+UPDATE acl_grants
+SET
+    status = 'active',
+    revoke_txn_id = NULL
+WHERE id = $1;
+
+-- name: RearmGrantPQ :one
+UPDATE acl_grants
+SET
+    status = 'active',
+    revoke_txn_id = NULL,
+    revoke_attempts = revoke_attempts + 1,
+    expires_at = LEAST(expires_at, NOW() + LEAST(INTERVAL '1 minute' * POWER(2, revoke_attempts), INTERVAL '1 hour'))
+WHERE revoke_txn_id = $1 AND status IN ('expired', 'revoked')
+RETURNING *;
@@ -0,0 +1,56 @@
+-- name: CreateDeferredTransactionPQ :one
+INSERT INTO deferred_transactions (
+    id,
+    username,
+    execute_at,
+    transaction
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetDeferredTransactionPQ :one
+SELECT * FROM deferred_transactions
+WHERE id = $1;
+
+-- name: GetScheduledDeferredTransactionsByUserPQ :many
+SELECT * FROM deferred_transactions
+WHERE username = $1 AND status = 'scheduled'
+ORDER BY execute_at ASC;
+
+-- name: ClaimDueDeferredTransactionsPQ :many
+WITH due AS (
+    UPDATE deferred_transactions
+    SET
+        status = 'dispatched',
+        dispatched_at = NOW()
+    WHERE id IN (
+        SELECT id FROM deferred_transactions
+        WHERE status = 'scheduled' AND execute_at <= NOW()
+        ORDER BY execute_at ASC
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *
+), queued AS (
+    INSERT INTO queued_transactions (id, username, owner, transaction)
+    SELECT id, username, $2, transaction FROM due
+    ON CONFLICT (id) DO NOTHING
+)
+SELECT * FROM due;
+
+-- name: ResetDeferredTransactionPQ :exec
+WITH unqueued AS (
+    DELETE FROM queued_transactions
+    WHERE id = $1 AND status = 'queued'
+)
+UPDATE deferred_transactions
+SET
+    status = 'scheduled',
+    dispatched_at = NULL
+WHERE id = $1;
+
+-- name: CancelDeferredTransactionPQ :one
+UPDATE deferred_transactions
+SET status = 'cancelled'
+WHERE id = $1 AND username = $2 AND status = 'scheduled'
+RETURNING *;
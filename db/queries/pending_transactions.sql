@@ -11,15 +11,42 @@ INSERT INTO pending_transactions_archive (
     output,
     executed_by,
     duration_ms,
-    ExecStatus
+    ExecStatus,
+    source_path,
+    attempts,
+    last_error,
+    steps,
+    recursive,
+    acl,
+    expected_acl,
+    priority
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
-) RETURNING *;
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+)
+ON CONFLICT (id) DO UPDATE SET
+    session_id = EXCLUDED.session_id,
+    status = EXCLUDED.status,
+    error_msg = EXCLUDED.error_msg,
+    output = EXCLUDED.output,
+    duration_ms = EXCLUDED.duration_ms,
+    ExecStatus = EXCLUDED.ExecStatus,
+    attempts = EXCLUDED.attempts,
+    last_error = EXCLUDED.last_error
+RETURNING *;
 
 -- name: GetPendingTransactionPQ :one
 SELECT * FROM pending_transactions_archive
 WHERE id = $1;
 
+-- name: GetPendingTransactionsByUserPQ :many
+SELECT * FROM pending_transactions_archive
+WHERE executed_by = $1 AND status = 'pending'
+ORDER BY timestamp ASC;
+
+-- name: GetPendingTransactionUsersPQ :many
+SELECT DISTINCT executed_by FROM pending_transactions_archive
+WHERE status = 'pending';
+
 -- name: GetPendingTransactionsBySessionPQ :many
 SELECT * FROM pending_transactions_archive
 WHERE session_id = $1
@@ -0,0 +1,63 @@
+-- name: EnqueueTransactionPQ :exec
+INSERT INTO queued_transactions (
+    id,
+    username,
+    owner,
+    transaction
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (id) DO UPDATE SET
+    username = EXCLUDED.username,
+    owner = EXCLUDED.owner,
+    status = 'queued',
+    transaction = EXCLUDED.transaction,
+    claimed_at = NULL;
+
+-- name: ClaimQueuedTransactionPQ :exec
+UPDATE queued_transactions
+SET
+    status = 'processing',
+    claimed_at = NOW()
+WHERE id = $1;
+
+-- name: AckQueuedTransactionPQ :exec
+DELETE FROM queued_transactions
+WHERE id = $1;
+
+-- name: ReclaimQueuedTransactionsPQ :many
+UPDATE queued_transactions
+SET
+    owner = $1,
+    status = 'queued',
+    claimed_at = NULL
+WHERE id IN (
+    SELECT q.id FROM queued_transactions q
+    LEFT JOIN backend_instances i ON i.id = q.owner
+    WHERE q.owner <> $1 AND (i.id IS NULL OR i.heartbeat_at < $3)
+    ORDER BY q.enqueued_at ASC
+    LIMIT $2
+    FOR UPDATE OF q SKIP LOCKED
+)
+RETURNING *;
+
+-- name: HeartbeatInstancePQ :exec
+INSERT INTO backend_instances (
+    id
+) VALUES (
+    $1
+)
+ON CONFLICT (id) DO UPDATE SET
+    heartbeat_at = NOW();
+
+-- name: ReleaseInstancePQ :exec
+DELETE FROM backend_instances
+WHERE id = $1;
+
+-- name: PruneInstancesPQ :exec
+DELETE FROM backend_instances i
+WHERE i.heartbeat_at < $1
+AND NOT EXISTS (
+    SELECT 1 FROM queued_transactions q
+    WHERE q.owner = i.id
+);
@@ -11,9 +11,16 @@ INSERT INTO results_transactions_archive (
     output,
     executed_by,
     duration_ms,
-    ExecStatus
+    ExecStatus,
+    acl,
+    before_acl,
+    source_path,
+    attempts,
+    last_error,
+    steps,
+    priority
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 ) RETURNING *;
 
 -- name: GetResultsTransactionPQ :one
@@ -1,4 +1,5 @@
 -- database schema for sqlc code generation for archival PostgreSQL
+-- existing databases are upgraded with db/migrations, every change here needs a new migration
 
 CREATE TABLE IF NOT EXISTS sessions_archive (
     id              UUID PRIMARY KEY,
@@ -18,7 +19,7 @@ CREATE TABLE IF NOT EXISTS pending_transactions_archive (
     id UUID PRIMARY KEY,
     session_id UUID NOT NULL,
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    operation VARCHAR(20) NOT NULL CHECK (operation IN ('getfacl', 'setfacl')),
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl', 'changeset')),
     target_path TEXT NOT NULL,
     entries JSONB NOT NULL DEFAULT '[]'::jsonb,
     status TEXT CHECK (status IN ('pending')) NOT NULL,
@@ -27,23 +28,81 @@ CREATE TABLE IF NOT EXISTS pending_transactions_archive (
     executed_by VARCHAR(255) NOT NULL,
     duration_ms BIGINT,
     ExecStatus BOOLEAN NOT NULL DEFAULT FALSE,
-    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    source_path TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    steps JSONB NOT NULL DEFAULT '[]'::jsonb,
+    recursive BOOLEAN NOT NULL DEFAULT FALSE,
+    acl JSONB NOT NULL DEFAULT '[]'::jsonb,
+    expected_acl JSONB NOT NULL DEFAULT '[]'::jsonb,
+    priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent'))
 );
 
 CREATE TABLE IF NOT EXISTS results_transactions_archive (
     id UUID PRIMARY KEY,
     session_id UUID NOT NULL,
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    operation VARCHAR(20) NOT NULL CHECK (operation IN ('getfacl', 'setfacl')),
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('getfacl', 'setfacl', 'restoreacl', 'copyacl', 'changeset')),
     target_path TEXT NOT NULL,
     entries JSONB NOT NULL DEFAULT '[]'::jsonb,
-    status TEXT CHECK (status IN ('success', 'failed')) NOT NULL,
+    status TEXT CHECK (status IN ('success', 'failed', 'cancelled')) NOT NULL,
     error_msg TEXT,
     output TEXT,
     executed_by VARCHAR(255) NOT NULL,
     duration_ms BIGINT,
     ExecStatus BOOLEAN NOT NULL DEFAULT FALSE,
-    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    acl JSONB NOT NULL DEFAULT '[]'::jsonb,
+    before_acl JSONB NOT NULL DEFAULT '[]'::jsonb,
+    source_path TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    steps JSONB NOT NULL DEFAULT '[]'::jsonb,
+    priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('low', 'normal', 'high', 'urgent'))
+);
+
+CREATE TABLE IF NOT EXISTS queued_transactions (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    owner UUID NOT NULL,
+    status TEXT CHECK (status IN ('queued', 'processing')) NOT NULL DEFAULT 'queued',
+    transaction JSONB NOT NULL,
+    enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS backend_instances (
+    id UUID PRIMARY KEY,
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS deferred_transactions (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    execute_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status TEXT CHECK (status IN ('scheduled', 'dispatched', 'cancelled')) NOT NULL DEFAULT 'scheduled',
+    transaction JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS acl_grants (
+    id UUID PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    txn_id UUID NOT NULL,
+    target_path TEXT NOT NULL,
+    entity_type VARCHAR(10) NOT NULL CHECK (entity_type IN ('user', 'group')),
+    entity TEXT NOT NULL,
+    permissions VARCHAR(3) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    recursive BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status TEXT CHECK (status IN ('active', 'expired', 'revoked', 'superseded')) NOT NULL DEFAULT 'active',
+    revoke_txn_id UUID,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoke_attempts INTEGER NOT NULL DEFAULT 0
 );
 
 /* add indexing for optimization */
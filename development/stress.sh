@@ -28,11 +28,11 @@ while true; do
       -d '{
         "operation": "setfacl",
         "targetPath": "/mnt/nfs-system/home/maverick/linux-acl-management-aclapi/README.md",
-        "entries": {
+        "entries": [{
             "entityType": "user",
             "entity": "tommy",
             "permissions": "rw-",
             "action": "modify"
-          }
+          }]
       }'
 done
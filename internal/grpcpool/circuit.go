@@ -0,0 +1,201 @@
+package grpcpool
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+)
+
+/*
+	every daemon has a circuit breaker
+	consecutive failures open the circuit and transactions for the daemon are parked by the scheduler
+	MonitorHealth keeps pinging a daemon with an open circuit and half-opens it once the daemon answers
+	a half-open circuit lets a single trial transaction through, it closes only if the trial succeeds
+	and opens again if it fails
+*/
+
+/* sets the function called when the circuit of a daemon half-opens or closes, parked transactions may run again */
+func (p *ClientPool) OnCircuitClose(fn func(addr string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onCircuitClose = fn
+}
+
+/* reports if the circuit of a daemon is open, a half-open circuit is open while its trial runs */
+func (p *ClientPool) CircuitOpen(addr string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	c, ok := p.circuits[addr]
+	return ok && c.open && !(c.halfOpen && c.trialTxn == uuid.Nil)
+}
+
+/*
+takes the request of a transaction to a daemon, false if its circuit is open
+the first transaction on a half-open circuit becomes its trial, the circuit stays open for others until the trial ends
+the trial may take the circuit again, a transaction can touch several filesystem servers of one daemon
+*/
+func (p *ClientPool) AcquireCircuit(addr string, txnID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.circuits[addr]
+	if !ok || !c.open {
+		return true
+	}
+	if !c.halfOpen {
+		return false
+	}
+	if c.trialTxn != uuid.Nil {
+		return c.trialTxn == txnID
+	}
+
+	c.trialTxn = txnID
+	zap.L().Info("Circuit half-open for daemon, sending trial transaction",
+		zap.String("Address", addr),
+		zap.String("txnID", txnID.String()),
+	)
+
+	return true
+}
+
+/*
+called once a transaction holding a request to a daemon finished, whatever its outcome
+a trial that didn't report an outcome (cancelled, or never reached the daemon) is given up, the next transaction becomes the trial
+*/
+func (p *ClientPool) ReleaseCircuit(addr string, txnID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.circuits[addr]
+	if !ok || !c.halfOpen || c.trialTxn != txnID {
+		return
+	}
+
+	c.trialTxn = uuid.Nil
+	zap.L().Info("Trial transaction ended without an outcome, circuit stays half-open for daemon",
+		zap.String("Address", addr),
+		zap.String("txnID", txnID.String()),
+	)
+}
+
+/*
+counts a failed request of a transaction to a daemon, opens its circuit once failure_threshold is reached
+while half-open only the failure of the trial counts, it opens the circuit again
+*/
+func (p *ClientPool) RecordFailure(addr string, txnID uuid.UUID, errCh chan<- error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.circuits[addr]
+	if !ok {
+		c = &circuit{}
+		p.circuits[addr] = c
+	}
+
+	/* the trial failed, wait for the next answered ping */
+	if c.halfOpen {
+		if c.trialTxn != txnID {
+			return
+		}
+		c.halfOpen = false
+		c.trialTxn = uuid.Nil
+		zap.L().Warn("Trial transaction failed, circuit opened again for daemon",
+			zap.String("Address", addr),
+			zap.String("txnID", txnID.String()),
+		)
+		return
+	}
+
+	c.failures++
+	if c.open || c.failures < failureThreshold(addr) {
+		return
+	}
+
+	c.open = true
+	zap.L().Warn("Circuit opened for daemon, parking its transactions",
+		zap.String("Address", addr),
+		zap.Int("failures", c.failures),
+	)
+
+	/* the daemon must be monitored to notice it recovering */
+	if _, exists := p.conns[addr]; !exists {
+		if _, err := p.dialLocked(addr, errCh); err != nil {
+			errCh <- fmt.Errorf("failed to monitor daemon at %s: %w", addr, err)
+		}
+	}
+}
+
+/*
+counts a successful request of a transaction to a daemon, failures have to be consecutive to open the circuit
+only the success of the trial closes a half-open circuit
+*/
+func (p *ClientPool) RecordSuccess(addr string, txnID uuid.UUID) {
+	p.mu.Lock()
+	c, ok := p.circuits[addr]
+	if !ok || (c.open && (!c.halfOpen || c.trialTxn != txnID)) {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.circuits, addr)
+	onClose := p.onCircuitClose
+	p.mu.Unlock()
+
+	if !c.open {
+		return
+	}
+
+	zap.L().Info("Trial transaction succeeded, circuit closed for daemon",
+		zap.String("Address", addr),
+		zap.String("txnID", txnID.String()),
+	)
+
+	if onClose != nil {
+		onClose(addr)
+	}
+}
+
+/* half-opens the circuit of a daemon that answered a ping, so a trial transaction can be dispatched */
+func (p *ClientPool) halfOpenCircuit(addr string) {
+	p.mu.Lock()
+	c, ok := p.circuits[addr]
+	if !ok || !c.open || c.halfOpen {
+		p.mu.Unlock()
+		return
+	}
+	c.halfOpen = true
+	c.trialTxn = uuid.Nil
+	onClose := p.onCircuitClose
+	p.mu.Unlock()
+
+	zap.L().Info("Circuit half-open for daemon",
+		zap.String("Address", addr),
+	)
+
+	if onClose != nil {
+		onClose(addr)
+	}
+}
+
+/* failures opening the circuit of a daemon, from the filesystem server it serves */
+func failureThreshold(addr string) int {
+	threshold := 0
+	for _, server := range config.BackendConfig.FileSystemServers {
+		if server.Remote == nil || fmt.Sprintf("%s:%d", server.Remote.Host, server.Remote.Port) != addr {
+			continue
+		}
+		/* daemons serving several paths use the lowest threshold */
+		if threshold == 0 || server.Remote.FailureThreshold < threshold {
+			threshold = server.Remote.FailureThreshold
+		}
+	}
+
+	if threshold <= 0 {
+		return 5
+	}
+	return threshold
+}
@@ -0,0 +1,216 @@
+package grpcpool
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+)
+
+/* daemon under test, its circuit opens after two failures */
+const testDaemon = "daemon.test:2310"
+
+/* step taken on the circuit of the test daemon by the transaction with the given index */
+type circuitStep struct {
+	action string
+	txn    int
+
+	/* result of acquire */
+	wantAcquired bool
+
+	/* CircuitOpen after the step */
+	wantOpen bool
+}
+
+/* pool whose test daemon counts as monitored already, opening its circuit doesn't dial it */
+func newTestPool(t *testing.T) *ClientPool {
+	previous := config.BackendConfig.FileSystemServers
+	config.BackendConfig.FileSystemServers = []config.FileSystemServers{
+		{Path: "/srv/remote", Remote: &config.Remote{Host: "daemon.test", Port: 2310, FailureThreshold: 2}},
+	}
+	t.Cleanup(func() { config.BackendConfig.FileSystemServers = previous })
+
+	p := NewClientPool()
+	p.conns[testDaemon] = nil
+	return p
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	/* opens the circuit of the test daemon */
+	opened := []circuitStep{
+		{action: "fail", txn: 0, wantOpen: false},
+		{action: "fail", txn: 0, wantOpen: true},
+	}
+
+	/* half-opens the opened circuit and starts transaction 1 as its trial */
+	trial := append(append([]circuitStep(nil), opened...),
+		circuitStep{action: "ping", wantOpen: false},
+		circuitStep{action: "acquire", txn: 1, wantAcquired: true, wantOpen: true},
+	)
+
+	tests := []struct {
+		name  string
+		steps []circuitStep
+
+		/* calls of the function set with OnCircuitClose */
+		wantNotified int
+	}{
+		{
+			name: "failures must be consecutive",
+			steps: []circuitStep{
+				{action: "fail", txn: 0, wantOpen: false},
+				{action: "succeed", txn: 1, wantOpen: false},
+				{action: "fail", txn: 2, wantOpen: false},
+				{action: "acquire", txn: 3, wantAcquired: true, wantOpen: false},
+			},
+		},
+		{
+			name: "open circuit parks transactions",
+			steps: append(append([]circuitStep(nil), opened...),
+				circuitStep{action: "acquire", txn: 1, wantAcquired: false, wantOpen: true},
+				circuitStep{action: "succeed", txn: 1, wantOpen: true},
+			),
+		},
+		{
+			name: "ping on a closed circuit changes nothing",
+			steps: []circuitStep{
+				{action: "ping", wantOpen: false},
+				{action: "acquire", txn: 1, wantAcquired: true, wantOpen: false},
+			},
+		},
+		{
+			name: "successful trial closes the circuit",
+			steps: append(append([]circuitStep(nil), trial...),
+				circuitStep{action: "acquire", txn: 2, wantAcquired: false, wantOpen: true},
+
+				/* the trial touches another filesystem server of the daemon */
+				circuitStep{action: "acquire", txn: 1, wantAcquired: true, wantOpen: true},
+				circuitStep{action: "succeed", txn: 1, wantOpen: false},
+				circuitStep{action: "acquire", txn: 2, wantAcquired: true, wantOpen: false},
+
+				/* failures are counted from zero again */
+				circuitStep{action: "fail", txn: 2, wantOpen: false},
+			),
+			wantNotified: 2,
+		},
+		{
+			name: "failed trial opens the circuit again",
+			steps: append(append([]circuitStep(nil), trial...),
+				circuitStep{action: "fail", txn: 1, wantOpen: true},
+				circuitStep{action: "acquire", txn: 2, wantAcquired: false, wantOpen: true},
+				circuitStep{action: "ping", wantOpen: false},
+				circuitStep{action: "acquire", txn: 2, wantAcquired: true, wantOpen: true},
+			),
+			wantNotified: 2,
+		},
+		{
+			name: "outcomes of other transactions don't decide the trial",
+			steps: append(append([]circuitStep(nil), trial...),
+				circuitStep{action: "fail", txn: 0, wantOpen: true},
+				circuitStep{action: "succeed", txn: 0, wantOpen: true},
+				circuitStep{action: "release", txn: 0, wantOpen: true},
+				circuitStep{action: "succeed", txn: 1, wantOpen: false},
+			),
+			wantNotified: 2,
+		},
+		{
+			name: "trial released without an outcome is given up",
+			steps: append(append([]circuitStep(nil), trial...),
+				circuitStep{action: "release", txn: 1, wantOpen: false},
+				circuitStep{action: "acquire", txn: 2, wantAcquired: true, wantOpen: true},
+
+				/* a late outcome of the old trial is ignored */
+				circuitStep{action: "succeed", txn: 1, wantOpen: true},
+				circuitStep{action: "succeed", txn: 2, wantOpen: false},
+			),
+			wantNotified: 2,
+		},
+		{
+			name: "release after the trial succeeded",
+			steps: append(append([]circuitStep(nil), trial...),
+				circuitStep{action: "succeed", txn: 1, wantOpen: false},
+				circuitStep{action: "release", txn: 1, wantOpen: false},
+			),
+			wantNotified: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPool(t)
+			errCh := make(chan error, 10)
+
+			notified := 0
+			p.OnCircuitClose(func(addr string) {
+				if addr != testDaemon {
+					t.Errorf("notified for %s", addr)
+				}
+				notified++
+			})
+
+			txns := make([]uuid.UUID, 4)
+			for i := range txns {
+				txns[i] = uuid.New()
+			}
+
+			for i, step := range tt.steps {
+				txnID := txns[step.txn]
+				switch step.action {
+				case "fail":
+					p.RecordFailure(testDaemon, txnID, errCh)
+				case "succeed":
+					p.RecordSuccess(testDaemon, txnID)
+				case "ping":
+					p.halfOpenCircuit(testDaemon)
+				case "acquire":
+					if got := p.AcquireCircuit(testDaemon, txnID); got != step.wantAcquired {
+						t.Errorf("step %d: acquire by %d = %v, want %v", i, step.txn, got, step.wantAcquired)
+					}
+				case "release":
+					p.ReleaseCircuit(testDaemon, txnID)
+				default:
+					t.Fatalf("step %d: unknown action %q", i, step.action)
+				}
+
+				if got := p.CircuitOpen(testDaemon); got != step.wantOpen {
+					t.Errorf("step %d (%s by %d): open = %v, want %v", i, step.action, step.txn, got, step.wantOpen)
+				}
+			}
+
+			if notified != tt.wantNotified {
+				t.Errorf("notified %d times, want %d", notified, tt.wantNotified)
+			}
+			if len(errCh) != 0 {
+				t.Errorf("reported %v", <-errCh)
+			}
+		})
+	}
+}
+
+func TestFailureThreshold(t *testing.T) {
+	previous := config.BackendConfig.FileSystemServers
+	t.Cleanup(func() { config.BackendConfig.FileSystemServers = previous })
+
+	config.BackendConfig.FileSystemServers = []config.FileSystemServers{
+		{Path: "/srv/a", Remote: &config.Remote{Host: "a.test", Port: 2310, FailureThreshold: 3}},
+		{Path: "/srv/b", Remote: &config.Remote{Host: "a.test", Port: 2310, FailureThreshold: 2}},
+		{Path: "/srv/c", Remote: &config.Remote{Host: "c.test", Port: 2310}},
+		{Path: "/srv/local"},
+	}
+
+	tests := []struct {
+		addr string
+		want int
+	}{
+		{addr: "a.test:2310", want: 2},
+		{addr: "c.test:2310", want: 5},
+		{addr: "unknown.test:2310", want: 5},
+	}
+
+	for _, tt := range tests {
+		if got := failureThreshold(tt.addr); got != tt.want {
+			t.Errorf("failureThreshold(%s) = %d, want %d", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ func NewClientPool(opts ...grpc.DialOption) *ClientPool {
 		conns:       make(map[string]*grpc.ClientConn),
 		dialOptions: opts,
 		stopCh:      make(chan struct{}),
+		circuits:    make(map[string]*circuit),
 	}
 }
 
@@ -40,6 +41,11 @@ func (p *ClientPool) GetConn(addr string, errCh chan<- error) (*grpc.ClientConn,
 		return conn, nil
 	}
 
+	return p.dialLocked(addr, errCh)
+}
+
+/* creates a connection and starts monitoring it - assumes caller holds p.mu */
+func (p *ClientPool) dialLocked(addr string, errCh chan<- error) (*grpc.ClientConn, error) {
 	/* create a new client for gRPC server */
 	newConn, err := grpc.NewClient(addr, p.dialOptions...)
 	if err != nil {
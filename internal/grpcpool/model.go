@@ -1,6 +1,7 @@
 package grpcpool
 
 import (
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"sync"
 )
@@ -11,4 +12,22 @@ type ClientPool struct {
 	conns       map[string]*grpc.ClientConn
 	dialOptions []grpc.DialOption
 	stopCh      chan struct{}
+
+	/* circuit breakers of daemons by address, guarded by mu */
+	circuits map[string]*circuit
+
+	/* called when the circuit of a daemon half-opens or closes again */
+	onCircuitClose func(addr string)
+}
+
+/* circuit breaker of a daemon, opened after consecutive failures */
+type circuit struct {
+	failures int
+	open     bool
+
+	/* the daemon answered a ping while open, a single trial request decides about closing */
+	halfOpen bool
+
+	/* transaction sent as the trial, uuid.Nil while none is running */
+	trialTxn uuid.UUID
 }
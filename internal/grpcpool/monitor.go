@@ -30,6 +30,11 @@ func (p *ClientPool) MonitorHealth(addr string, conn *grpc.ClientConn, errCh cha
 				errCh <- fmt.Errorf("ping failed for daemon at %s: %w", addr, err)
 
 				p.mu.Lock()
+				/* while the circuit is open the daemon keeps being pinged, a successful ping half-opens it */
+				if c, ok := p.circuits[addr]; ok && c.open {
+					p.mu.Unlock()
+					continue
+				}
 				conn.Close()
 				delete(p.conns, addr)
 				p.mu.Unlock()
@@ -39,6 +44,7 @@ func (p *ClientPool) MonitorHealth(addr string, conn *grpc.ClientConn, errCh cha
 				zap.L().Info("Ping success",
 					zap.String("Address", addr),
 				)
+				p.halfOpenCircuit(addr)
 			}
 		}
 	}
@@ -0,0 +1,397 @@
+package posixacl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* converts access and default ACL into rules, as getfacl lists them */
+func (f *FileACL) Rules() []types.ACLRule {
+	rules := make([]types.ACLRule, 0, len(f.Access)+len(f.Default))
+	rules = appendRules(rules, f.Access, false)
+	rules = appendRules(rules, f.Default, true)
+	return rules
+}
+
+/* appends the entries of an ACL as rules */
+func appendRules(rules []types.ACLRule, acl ACL, isDefault bool) []types.ACLRule {
+	for _, entry := range acl.Sorted() {
+		rule := types.ACLRule{
+			EntityType:  entry.Tag.String(),
+			Permissions: entry.Perm.String(),
+			IsDefault:   isDefault,
+		}
+
+		switch entry.Tag {
+		case TagUser:
+			rule.Entity = UserName(entry.ID)
+		case TagGroup:
+			rule.Entity = GroupName(entry.ID)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+/* reports if two rule lists describe the same ACL, regardless of order */
+func EqualRules(a, b []types.ACLRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[types.ACLRule]int, len(a))
+	for _, rule := range a {
+		counts[rule]++
+	}
+	for _, rule := range b {
+		if counts[rule] == 0 {
+			return false
+		}
+		counts[rule]--
+	}
+
+	return true
+}
+
+/*
+replaces access and default ACL with the given rules, as they were captured by Rules
+default rules are skipped on files since only directories can carry them
+*/
+func (f *FileACL) Restore(rules []types.ACLRule) error {
+	restored, err := FromRules(rules, f.IsDir)
+	if err != nil {
+		return err
+	}
+
+	f.Access = restored.Access
+	f.Default = nil
+	if f.IsDir {
+		f.Default = restored.Default
+	}
+	f.markChanged(false)
+	f.markChanged(true)
+
+	return nil
+}
+
+/*
+applies a single ACL entry in memory with setfacl semantics
+"add"/"modify" behave like setfacl -m, "remove" like setfacl -x and "set" like setfacl --set
+"remove-all" (setfacl -b) and "remove-default" (setfacl -k) don't refer to an entity
+nothing is written until Write is called, so several entries can be applied as one unit
+*/
+func (f *FileACL) Apply(entry types.ACLEntry) error {
+	/* "X" is decided by the state before the transaction, not by earlier entries of it */
+	if !f.executableKnown {
+		f.executable = f.hasExecute()
+		f.executableKnown = true
+	}
+
+	switch entry.Action {
+	case "remove-all":
+		/* only the owner, owning group and other entries are kept */
+		base := make(ACL, 0, 3)
+		for _, e := range f.Access {
+			if e.Tag == TagUserObj || e.Tag == TagGroupObj || e.Tag == TagOther {
+				base = append(base, e)
+			}
+		}
+		f.Access = base
+		f.Default = nil
+		f.markChanged(false)
+		f.markChanged(true)
+		return nil
+
+	case "remove-default":
+		f.Default = nil
+		f.markChanged(true)
+		return nil
+	}
+
+	tag, id, err := resolveEntity(entry.EntityType, entry.Entity)
+	if err != nil {
+		return err
+	}
+
+	if entry.IsDefault && !f.IsDir {
+		return fmt.Errorf("default ACL can only be set on directories")
+	}
+
+	/* reject a bad mask mode before anything is changed */
+	if err := validateMaskMode(entry); err != nil {
+		return err
+	}
+
+	target := &f.Access
+	if entry.IsDefault {
+		target = &f.Default
+	}
+
+	switch entry.Action {
+	case "add", "modify":
+		if entry.Permissions == "" {
+			return fmt.Errorf("permissions are required for %s", entry.Action)
+		}
+
+		perm, err := ParsePerm(f.resolveConditionalExecute(entry.Permissions))
+		if err != nil {
+			return err
+		}
+
+		/* like setfacl, a new default ACL starts from the base entries of the access ACL */
+		if entry.IsDefault && len(f.Default) == 0 {
+			for _, base := range f.Access {
+				if base.Tag == TagUserObj || base.Tag == TagGroupObj || base.Tag == TagOther {
+					f.Default = append(f.Default, base)
+				}
+			}
+		}
+
+		if idx, ok := target.Find(tag, id); ok {
+			(*target)[idx].Perm = perm
+		} else {
+			*target = append(*target, Entry{Tag: tag, Perm: perm, ID: id})
+		}
+
+		if tag == TagMask {
+			f.markMaskSet(entry.IsDefault)
+		}
+
+	case "set":
+		if entry.Permissions == "" {
+			return fmt.Errorf("permissions are required for %s", entry.Action)
+		}
+
+		perm, err := ParsePerm(f.resolveConditionalExecute(entry.Permissions))
+		if err != nil {
+			return err
+		}
+
+		/* the first "set" entry of an ACL drops everything it held before */
+		if !f.isReplaced(entry.IsDefault) {
+			*target = nil
+			f.markReplaced(entry.IsDefault)
+		}
+
+		if idx, ok := target.Find(tag, id); ok {
+			(*target)[idx].Perm = perm
+		} else {
+			*target = append(*target, Entry{Tag: tag, Perm: perm, ID: id})
+		}
+
+		if tag == TagMask {
+			f.markMaskSet(entry.IsDefault)
+		}
+
+	case "remove":
+		switch tag {
+		case TagUserObj, TagGroupObj, TagOther:
+			return fmt.Errorf("base ACL entries cannot be removed")
+		case TagMask:
+			if target.HasNamed() {
+				return fmt.Errorf("mask is required while named entries exist")
+			}
+		}
+
+		/* removing a missing entry is not an error (same as setfacl -x) */
+		if idx, ok := target.Find(tag, id); ok {
+			*target = append((*target)[:idx], (*target)[idx+1:]...)
+		}
+
+	default:
+		return fmt.Errorf("unsupported ACL action: %s", entry.Action)
+	}
+
+	f.markChanged(entry.IsDefault)
+
+	return f.applyMaskMode(entry)
+}
+
+/* checks the mask mode and mask permissions of an entry */
+func validateMaskMode(entry types.ACLEntry) error {
+	switch entry.MaskMode {
+	case "", types.MaskRecalculate, types.MaskPreserve:
+		return nil
+	case types.MaskSet:
+		if entry.MaskPermissions == "" {
+			return fmt.Errorf("mask permissions are required to set the mask")
+		}
+		_, err := ParsePerm(strings.ReplaceAll(entry.MaskPermissions, "X", "x"))
+		return err
+	}
+
+	return fmt.Errorf("unsupported mask mode: %s", entry.MaskMode)
+}
+
+/* handles the mask of the ACL an entry belongs to as requested by the entry */
+func (f *FileACL) applyMaskMode(entry types.ACLEntry) error {
+	switch entry.MaskMode {
+	case "", types.MaskRecalculate:
+		return nil
+
+	case types.MaskPreserve:
+		f.markMaskSet(entry.IsDefault)
+		return nil
+
+	case types.MaskSet:
+		perm, err := ParsePerm(f.resolveConditionalExecute(entry.MaskPermissions))
+		if err != nil {
+			return err
+		}
+
+		target := &f.Access
+		if entry.IsDefault {
+			target = &f.Default
+		}
+
+		if idx, ok := target.Find(TagMask, undefinedID); ok {
+			(*target)[idx].Perm = perm
+		} else {
+			*target = append(*target, Entry{Tag: TagMask, Perm: perm, ID: undefinedID})
+		}
+
+		f.markMaskSet(entry.IsDefault)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported mask mode: %s", entry.MaskMode)
+}
+
+/*
+recalculates masks of the changed ACLs unless a mask was given explicitly or preserved
+a preserved ACL still gets a mask calculated if it needs one and has none
+ACLs replaced by "set" entries must hold the owner, owning group and other entries
+*/
+func (f *FileACL) Finalize() error {
+	if f.accessReplaced && !f.Access.hasBaseEntries() {
+		return fmt.Errorf("set requires user::, group:: and other:: entries for the access ACL")
+	}
+
+	if f.defaultReplaced && len(f.Default) != 0 && !f.Default.hasBaseEntries() {
+		return fmt.Errorf("set requires user::, group:: and other:: entries for the default ACL")
+	}
+
+	if f.accessChanged && (!f.accessMaskSet || f.Access.needsMask()) {
+		f.Access = f.Access.RecalculateMask()
+	}
+
+	if f.defaultChanged && (!f.defaultMaskSet || f.Default.needsMask()) && len(f.Default) != 0 {
+		f.Default = f.Default.RecalculateMask()
+	}
+
+	return nil
+}
+
+/* reports if an ACL holds the owner, owning group and other entries */
+func (a ACL) hasBaseEntries() bool {
+	_, hasUser := a.Find(TagUserObj, undefinedID)
+	_, hasGroup := a.Find(TagGroupObj, undefinedID)
+	_, hasOther := a.Find(TagOther, undefinedID)
+	return hasUser && hasGroup && hasOther
+}
+
+/* reports if an ACL has named entries but no mask */
+func (a ACL) needsMask() bool {
+	_, hasMask := a.Mask()
+	return a.HasNamed() && !hasMask
+}
+
+/*
+resolves the "X" permission like setfacl does:
+execute is granted only to directories and to files that already are executable for someone
+*/
+func (f *FileACL) resolveConditionalExecute(perms string) string {
+	if !strings.Contains(perms, "X") {
+		return perms
+	}
+
+	replacement := "-"
+	if f.IsDir || f.executable {
+		replacement = "x"
+	}
+
+	return strings.ReplaceAll(perms, "X", replacement)
+}
+
+/* reports if any of the owner, group (mask) or other classes can execute the file */
+func (f *FileACL) hasExecute() bool {
+	for _, entry := range f.Access {
+		switch entry.Tag {
+		case TagUserObj, TagMask, TagOther:
+			if entry.Perm&PermExecute != 0 {
+				return true
+			}
+		case TagGroupObj:
+			/* the owning group class is represented by the mask when there is one */
+			if _, hasMask := f.Access.Find(TagMask, undefinedID); !hasMask && entry.Perm&PermExecute != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* marks the access or default ACL as changed */
+func (f *FileACL) markChanged(isDefault bool) {
+	if isDefault {
+		f.defaultChanged = true
+	} else {
+		f.accessChanged = true
+	}
+}
+
+/* reports if the access or default ACL is being replaced by "set" entries */
+func (f *FileACL) isReplaced(isDefault bool) bool {
+	if isDefault {
+		return f.defaultReplaced
+	}
+	return f.accessReplaced
+}
+
+/* marks the access or default ACL as replaced by "set" entries */
+func (f *FileACL) markReplaced(isDefault bool) {
+	if isDefault {
+		f.defaultReplaced = true
+	} else {
+		f.accessReplaced = true
+	}
+}
+
+/* marks the mask of the access or default ACL as explicitly set */
+func (f *FileACL) markMaskSet(isDefault bool) {
+	if isDefault {
+		f.defaultMaskSet = true
+	} else {
+		f.accessMaskSet = true
+	}
+}
+
+/* maps an entity type and entity name to a tag and uid/gid */
+func resolveEntity(entityType, entity string) (Tag, uint32, error) {
+	switch entityType {
+	case "user", "u":
+		if entity == "" {
+			return TagUserObj, undefinedID, nil
+		}
+		uid, err := LookupUID(entity)
+		return TagUser, uid, err
+
+	case "group", "g":
+		if entity == "" {
+			return TagGroupObj, undefinedID, nil
+		}
+		gid, err := LookupGID(entity)
+		return TagGroup, gid, err
+
+	case "mask", "m":
+		return TagMask, undefinedID, nil
+
+	case "other", "o":
+		return TagOther, undefinedID, nil
+	}
+
+	return 0, 0, fmt.Errorf("unsupported entity type: %s", entityType)
+}
@@ -0,0 +1,358 @@
+package posixacl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	entities are numeric ids (setfacl accepts them too), so the tests don't depend on the users of the host
+	ids in the 40000 range are assumed not to be names of local users or groups
+*/
+
+/* applies entries and finalizes the result like a transaction does */
+func applyAll(f *FileACL, entries []types.ACLEntry) error {
+	for _, entry := range entries {
+		if err := f.Apply(entry); err != nil {
+			return err
+		}
+	}
+	return f.Finalize()
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		isDir   bool
+		access  string
+		def     string
+		entries []types.ACLEntry
+
+		wantAccess  string
+		wantDefault string
+		wantErr     string
+	}{
+		/* setfacl -m */
+		{
+			name:        "add named user recalculates mask",
+			access:      "user::rw-,group::r--,other::r--",
+			entries:     []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rwx", Action: "add"}},
+			wantAccess:  "user::rw-,user:40001:rwx,group::r--,mask::rwx,other::r--",
+			wantDefault: "",
+		},
+		{
+			name:       "modify narrows the mask",
+			access:     "user::rw-,user:40001:rwx,group::r--,mask::rwx,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "r--", Action: "modify"}},
+			wantAccess: "user::rw-,user:40001:r--,group::r--,mask::r--,other::r--",
+		},
+		{
+			name:       "owner and other entries",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Permissions: "rwx", Action: "modify"}, {EntityType: "other", Permissions: "---", Action: "modify"}},
+			wantAccess: "user::rwx,group::r--,other::---",
+		},
+		{
+			name:       "octal permissions",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "group", Entity: "40002", Permissions: "5", Action: "add"}},
+			wantAccess: "user::rw-,group::r--,group:40002:r-x,mask::r-x,other::r--",
+		},
+
+		/* mask control */
+		{
+			name:   "explicit mask entry is kept",
+			access: "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{
+				{EntityType: "user", Entity: "40001", Permissions: "rw-", Action: "add"},
+				{EntityType: "mask", Permissions: "r--", Action: "add"},
+			},
+			wantAccess: "user::rw-,user:40001:rw-,group::r--,mask::r--,other::r--",
+		},
+		{
+			name:       "preserved mask isn't recalculated",
+			access:     "user::rw-,user:40001:r--,group::r--,mask::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40002", Permissions: "rw-", Action: "add", MaskMode: types.MaskPreserve}},
+			wantAccess: "user::rw-,user:40001:r--,user:40002:rw-,group::r--,mask::r--,other::r--",
+		},
+		{
+			name:       "preserved mask is created when missing",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rw-", Action: "add", MaskMode: types.MaskPreserve}},
+			wantAccess: "user::rw-,user:40001:rw-,group::r--,mask::rw-,other::r--",
+		},
+		{
+			name:       "mask set with the entry",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rwx", Action: "add", MaskMode: types.MaskSet, MaskPermissions: "r-x"}},
+			wantAccess: "user::rw-,user:40001:rwx,group::r--,mask::r-x,other::r--",
+		},
+		{
+			name:    "mask set without permissions",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rwx", Action: "add", MaskMode: types.MaskSet}},
+			wantErr: "mask permissions are required",
+		},
+		{
+			name:    "unknown mask mode",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rwx", Action: "add", MaskMode: "keep"}},
+			wantErr: "unsupported mask mode",
+		},
+
+		/* conditional execute */
+		{
+			name:       "X on a file nobody can execute",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add"}},
+			wantAccess: "user::rw-,user:40001:r--,group::r--,mask::r--,other::r--",
+		},
+		{
+			name:       "X on an executable file",
+			access:     "user::rwx,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add"}},
+			wantAccess: "user::rwx,user:40001:r-x,group::r--,mask::r-x,other::r--",
+		},
+		{
+			name:       "X on a file executable through the mask",
+			access:     "user::rw-,user:40002:--x,group::r--,mask::r-x,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add"}},
+			wantAccess: "user::rw-,user:40001:r-x,user:40002:--x,group::r--,mask::r-x,other::r--",
+		},
+		{
+			name:       "X on a file with a masked out owning group",
+			access:     "user::rw-,group::r-x,mask::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add", MaskMode: types.MaskPreserve}},
+			wantAccess: "user::rw-,user:40001:r--,group::r-x,mask::r--,other::r--",
+		},
+		{
+			name:       "X on a directory",
+			isDir:      true,
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add"}},
+			wantAccess: "user::rw-,user:40001:r-x,group::r--,mask::r-x,other::r--",
+		},
+		{
+			name:   "X uses the state before the transaction",
+			access: "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{
+				{EntityType: "user", Permissions: "rwx", Action: "modify"},
+				{EntityType: "user", Entity: "40001", Permissions: "rX", Action: "add"},
+			},
+			wantAccess: "user::rwx,user:40001:r--,group::r--,mask::r--,other::r--",
+		},
+
+		/* setfacl -x */
+		{
+			name:       "remove named entry",
+			access:     "user::rw-,user:40001:rwx,group::r--,mask::rwx,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "user", Entity: "40001", Action: "remove"}},
+			wantAccess: "user::rw-,group::r--,mask::r--,other::r--",
+		},
+		{
+			name:       "remove missing entry",
+			access:     "user::rw-,group::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "group", Entity: "40002", Action: "remove"}},
+			wantAccess: "user::rw-,group::r--,other::r--",
+		},
+		{
+			name:       "remove mask without named entries",
+			access:     "user::rw-,group::r--,mask::r--,other::r--",
+			entries:    []types.ACLEntry{{EntityType: "mask", Action: "remove"}},
+			wantAccess: "user::rw-,group::r--,other::r--",
+		},
+		{
+			name:    "remove mask with named entries",
+			access:  "user::rw-,user:40001:r--,group::r--,mask::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "mask", Action: "remove"}},
+			wantErr: "mask is required",
+		},
+		{
+			name:    "remove base entry",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "group", Action: "remove"}},
+			wantErr: "base ACL entries cannot be removed",
+		},
+
+		/* setfacl -b and -k */
+		{
+			name:        "remove-all keeps the base entries",
+			isDir:       true,
+			access:      "user::rwx,user:40001:rwx,group::r-x,group:40002:r--,mask::rwx,other::r-x",
+			def:         "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+			entries:     []types.ACLEntry{{Action: "remove-all"}},
+			wantAccess:  "user::rwx,group::r-x,other::r-x",
+			wantDefault: "",
+		},
+		{
+			name:        "remove-default keeps the access ACL",
+			isDir:       true,
+			access:      "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+			def:         "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+			entries:     []types.ACLEntry{{Action: "remove-default"}},
+			wantAccess:  "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+			wantDefault: "",
+		},
+
+		/* setfacl --set */
+		{
+			name:   "set replaces the access ACL",
+			access: "user::rw-,user:40001:rwx,group::r--,mask::rwx,other::r--",
+			entries: []types.ACLEntry{
+				{EntityType: "user", Permissions: "rw-", Action: "set"},
+				{EntityType: "group", Permissions: "r--", Action: "set"},
+				{EntityType: "other", Permissions: "---", Action: "set"},
+				{EntityType: "user", Entity: "40002", Permissions: "r--", Action: "set"},
+			},
+			wantAccess: "user::rw-,user:40002:r--,group::r--,mask::r--,other::---",
+		},
+		{
+			name:   "set keeps the default ACL",
+			isDir:  true,
+			access: "user::rwx,group::r-x,other::r-x",
+			def:    "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+			entries: []types.ACLEntry{
+				{EntityType: "user", Permissions: "rwx", Action: "set"},
+				{EntityType: "group", Permissions: "---", Action: "set"},
+				{EntityType: "other", Permissions: "---", Action: "set"},
+			},
+			wantAccess:  "user::rwx,group::---,other::---",
+			wantDefault: "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+		},
+		{
+			name:    "set without base entries",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "r--", Action: "set"}},
+			wantErr: "set requires user::, group:: and other::",
+		},
+
+		/* default ACL */
+		{
+			name:        "new default ACL starts from the access base entries",
+			isDir:       true,
+			access:      "user::rwx,group::r-x,other::r-x",
+			entries:     []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "rwx", Action: "add", IsDefault: true}},
+			wantAccess:  "user::rwx,group::r-x,other::r-x",
+			wantDefault: "user::rwx,user:40001:rwx,group::r-x,mask::rwx,other::r-x",
+		},
+		{
+			name:    "default entry on a file",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "r--", Action: "add", IsDefault: true}},
+			wantErr: "default ACL can only be set on directories",
+		},
+
+		/* invalid entries */
+		{
+			name:    "add without permissions",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Action: "add"}},
+			wantErr: "permissions are required",
+		},
+		{
+			name:    "unknown action",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "user", Entity: "40001", Permissions: "r--", Action: "grant"}},
+			wantErr: "unsupported ACL action",
+		},
+		{
+			name:    "unknown entity type",
+			access:  "user::rw-,group::r--,other::r--",
+			entries: []types.ACLEntry{{EntityType: "role", Entity: "40001", Permissions: "r--", Action: "add"}},
+			wantErr: "unsupported entity type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &FileACL{
+				Access:  mustACL(t, tt.access),
+				Default: mustACL(t, tt.def),
+				IsDir:   tt.isDir,
+			}
+
+			err := applyAll(f, tt.entries)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if got := formatACL(f.Access); got != tt.wantAccess {
+				t.Errorf("access = %s, want %s", got, tt.wantAccess)
+			}
+			if got := formatACL(f.Default); got != tt.wantDefault {
+				t.Errorf("default = %s, want %s", got, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestMaskWarnings(t *testing.T) {
+	f := &FileACL{Access: mustACL(t, "user::rw-,group::r--,other::r--")}
+	entries := []types.ACLEntry{
+		{EntityType: "user", Entity: "40001", Permissions: "rw-", Action: "add", MaskMode: types.MaskSet, MaskPermissions: "r--"},
+		{EntityType: "group", Entity: "40002", Permissions: "r--", Action: "add"},
+		{EntityType: "other", Permissions: "rwx", Action: "modify"},
+	}
+	if err := applyAll(f, entries); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	/* only the named user loses a permission, other is never masked */
+	warnings := f.MaskWarnings(entries)
+	want := "user:40001 requested rw- but effective permissions are r-- (mask r--)"
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Errorf("MaskWarnings() = %q, want [%q]", warnings, want)
+	}
+}
+
+func TestEffectiveRights(t *testing.T) {
+	f := &FileACL{
+		Access:  mustACL(t, "user::rwx,user:40001:rwx,group::rw-,mask::r--,other::r--"),
+		Default: mustACL(t, "user::rwx,group:40002:rwx,group::r-x,mask::rwx,other::---"),
+		IsDir:   true,
+	}
+
+	want := []EffectiveRight{
+		{EntityType: "user", Entity: UserName(40001), Permissions: "rwx", Effective: "r--"},
+		{EntityType: "group", Permissions: "rw-", Effective: "r--"},
+		{EntityType: "group", Permissions: "r-x", Effective: "r-x", IsDefault: true},
+		{EntityType: "group", Entity: GroupName(40002), Permissions: "rwx", Effective: "rwx", IsDefault: true},
+	}
+
+	got := f.EffectiveRights()
+	if len(got) != len(want) {
+		t.Fatalf("EffectiveRights() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("EffectiveRights()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRulesRoundTrip(t *testing.T) {
+	f := &FileACL{
+		Access:  mustACL(t, "user::rwx,user:40001:r-x,group::r-x,mask::r-x,other::---"),
+		Default: mustACL(t, "user::rwx,group::r-x,other::---"),
+		IsDir:   true,
+	}
+
+	restored, err := FromRules(f.Rules(), false)
+	if err != nil {
+		t.Fatalf("FromRules() error = %v", err)
+	}
+	if !restored.IsDir {
+		t.Errorf("FromRules() IsDir = false, want true for an ACL with default rules")
+	}
+	if !EqualRules(restored.Rules(), f.Rules()) {
+		t.Errorf("FromRules(Rules()) = %+v, want %+v", restored.Rules(), f.Rules())
+	}
+}
@@ -0,0 +1,214 @@
+package posixacl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/* decodes the kernel's binary representation of an ACL */
+func Decode(data []byte) (ACL, error) {
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("ACL xattr too short: %d bytes", len(data))
+	}
+
+	/* check the header version */
+	if version := binary.LittleEndian.Uint32(data[:headerSize]); version != xattrVersion {
+		return nil, fmt.Errorf("unsupported ACL xattr version: %d", version)
+	}
+
+	body := data[headerSize:]
+	if len(body)%entrySize != 0 {
+		return nil, fmt.Errorf("ACL xattr has a truncated entry")
+	}
+
+	acl := make(ACL, 0, len(body)/entrySize)
+	for off := 0; off < len(body); off += entrySize {
+		entry := Entry{
+			Tag:  Tag(binary.LittleEndian.Uint16(body[off:])),
+			Perm: Perm(binary.LittleEndian.Uint16(body[off+2:])),
+			ID:   binary.LittleEndian.Uint32(body[off+4:]),
+		}
+
+		/* reject tags the kernel would never produce */
+		switch entry.Tag {
+		case TagUserObj, TagUser, TagGroupObj, TagGroup, TagMask, TagOther:
+		default:
+			return nil, fmt.Errorf("unknown ACL tag: 0x%x", uint16(entry.Tag))
+		}
+
+		acl = append(acl, entry)
+	}
+
+	return acl, nil
+}
+
+/* encodes an ACL into the kernel's binary representation (entries are sorted first) */
+func (a ACL) Encode() []byte {
+	sorted := a.Sorted()
+
+	data := make([]byte, headerSize+len(sorted)*entrySize)
+	binary.LittleEndian.PutUint32(data, xattrVersion)
+
+	off := headerSize
+	for _, entry := range sorted {
+		id := entry.ID
+		if !entry.Tag.IsNamed() {
+			id = undefinedID
+		}
+
+		binary.LittleEndian.PutUint16(data[off:], uint16(entry.Tag))
+		binary.LittleEndian.PutUint16(data[off+2:], uint16(entry.Perm))
+		binary.LittleEndian.PutUint32(data[off+4:], id)
+		off += entrySize
+	}
+
+	return data
+}
+
+/* returns a copy of the ACL sorted by tag and id, the order the kernel validates */
+func (a ACL) Sorted() ACL {
+	sorted := make(ACL, len(a))
+	copy(sorted, a)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Tag != sorted[j].Tag {
+			return sorted[i].Tag < sorted[j].Tag
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted
+}
+
+/* reports if an entry with the given tag and id exists */
+func (a ACL) Find(tag Tag, id uint32) (int, bool) {
+	for i, entry := range a {
+		if entry.Tag != tag {
+			continue
+		}
+		if !tag.IsNamed() || entry.ID == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+/* reports if the ACL has named user or group entries */
+func (a ACL) HasNamed() bool {
+	for _, entry := range a {
+		if entry.Tag.IsNamed() {
+			return true
+		}
+	}
+	return false
+}
+
+/* reports if the ACL only holds the owner, owning group and other entries */
+func (a ACL) IsMinimal() bool {
+	for _, entry := range a {
+		if entry.Tag != TagUserObj && entry.Tag != TagGroupObj && entry.Tag != TagOther {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+recalculates the mask as the union of the owning group and all named entries
+a mask is only kept when the ACL needs one or already had one
+*/
+func (a ACL) RecalculateMask() ACL {
+	var union Perm
+	for _, entry := range a {
+		if entry.Tag == TagGroupObj || entry.Tag.IsNamed() {
+			union |= entry.Perm
+		}
+	}
+
+	if idx, ok := a.Find(TagMask, undefinedID); ok {
+		a[idx].Perm = union
+		return a
+	}
+
+	if a.HasNamed() {
+		a = append(a, Entry{Tag: TagMask, Perm: union, ID: undefinedID})
+	}
+
+	return a
+}
+
+/* builds the minimal access ACL equivalent to the permission bits of a mode */
+func FromMode(mode uint32) ACL {
+	return ACL{
+		{Tag: TagUserObj, Perm: Perm(mode>>6) & 7, ID: undefinedID},
+		{Tag: TagGroupObj, Perm: Perm(mode>>3) & 7, ID: undefinedID},
+		{Tag: TagOther, Perm: Perm(mode) & 7, ID: undefinedID},
+	}
+}
+
+/* reports if entries of this tag refer to a uid or gid */
+func (t Tag) IsNamed() bool {
+	return t == TagUser || t == TagGroup
+}
+
+/* returns the entity type used by getfacl/setfacl for a tag */
+func (t Tag) String() string {
+	switch t {
+	case TagUserObj, TagUser:
+		return "user"
+	case TagGroupObj, TagGroup:
+		return "group"
+	case TagMask:
+		return "mask"
+	case TagOther:
+		return "other"
+	}
+	return fmt.Sprintf("tag(0x%x)", uint16(t))
+}
+
+/* renders permissions as in getfacl, e.g. "r-x" */
+func (p Perm) String() string {
+	var sb strings.Builder
+
+	for _, bit := range []struct {
+		perm Perm
+		char byte
+	}{{PermRead, 'r'}, {PermWrite, 'w'}, {PermExecute, 'x'}} {
+		if p&bit.perm != 0 {
+			sb.WriteByte(bit.char)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+
+	return sb.String()
+}
+
+/*
+parses permissions in setfacl syntax: "rwx", "r-x", "rw" or a single octal digit
+a conditional "X" must be resolved by the caller beforehand
+*/
+func ParsePerm(s string) (Perm, error) {
+	if len(s) == 1 && s[0] >= '0' && s[0] <= '7' {
+		return Perm(s[0] - '0'), nil
+	}
+
+	var perm Perm
+	for _, c := range s {
+		switch c {
+		case 'r':
+			perm |= PermRead
+		case 'w':
+			perm |= PermWrite
+		case 'x':
+			perm |= PermExecute
+		case '-':
+		default:
+			return 0, fmt.Errorf("invalid permission character %q in %q", c, s)
+		}
+	}
+
+	return perm, nil
+}
@@ -0,0 +1,244 @@
+package posixacl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+/* decodes a blob as printed by getfattr -e hex, spaces are only there for readability */
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(strings.ReplaceAll(strings.TrimPrefix(s, "0x"), " ", ""))
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+	return data
+}
+
+/* parses an ACL in getfacl short form, e.g. "user::rw-,user:1000:r--,mask::r--,other::r--" */
+func mustACL(t *testing.T, s string) ACL {
+	t.Helper()
+
+	var acl ACL
+	if s == "" {
+		return acl
+	}
+
+	for _, field := range strings.Split(s, ",") {
+		parts := strings.Split(field, ":")
+		if len(parts) != 3 {
+			t.Fatalf("invalid ACL entry %q", field)
+		}
+
+		perm, err := ParsePerm(parts[2])
+		if err != nil {
+			t.Fatalf("invalid ACL entry %q: %v", field, err)
+		}
+
+		entry := Entry{Perm: perm, ID: undefinedID}
+		switch {
+		case parts[0] == "user" && parts[1] == "":
+			entry.Tag = TagUserObj
+		case parts[0] == "user":
+			entry.Tag = TagUser
+		case parts[0] == "group" && parts[1] == "":
+			entry.Tag = TagGroupObj
+		case parts[0] == "group":
+			entry.Tag = TagGroup
+		case parts[0] == "mask":
+			entry.Tag = TagMask
+		case parts[0] == "other":
+			entry.Tag = TagOther
+		default:
+			t.Fatalf("invalid ACL entry %q", field)
+		}
+
+		if entry.Tag.IsNamed() {
+			id, err := parseID(parts[1])
+			if err != nil {
+				t.Fatalf("invalid ACL entry %q: %v", field, err)
+			}
+			entry.ID = id
+		}
+
+		acl = append(acl, entry)
+	}
+
+	return acl
+}
+
+/* renders an ACL in getfacl short form with numeric ids, entries in kernel order */
+func formatACL(acl ACL) string {
+	fields := make([]string, 0, len(acl))
+	for _, entry := range acl.Sorted() {
+		qualifier := ""
+		if entry.Tag.IsNamed() {
+			qualifier = fmt.Sprint(entry.ID)
+		}
+		fields = append(fields, fmt.Sprintf("%s:%s:%s", entry.Tag, qualifier, entry.Perm))
+	}
+	return strings.Join(fields, ",")
+}
+
+func TestDecodeEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		blob string
+		acl  string
+	}{
+		{
+			/* chmod 644, no extended entries */
+			name: "minimal",
+			blob: "0x02000000 0100 0600 ffffffff 0400 0400 ffffffff 2000 0400 ffffffff",
+			acl:  "user::rw-,group::r--,other::r--",
+		},
+		{
+			/* setfacl -m u:1000:r,g:1001:rx */
+			name: "named entries with mask",
+			blob: "0x02000000 0100 0600 ffffffff 0200 0400 e8030000 0400 0400 ffffffff 0800 0500 e9030000 1000 0500 ffffffff 2000 0400 ffffffff",
+			acl:  "user::rw-,user:1000:r--,group::r--,group:1001:r-x,mask::r-x,other::r--",
+		},
+		{
+			/* setfacl -d -m u:1000:rwx on a 755 directory */
+			name: "default ACL of a directory",
+			blob: "0x02000000 0100 0700 ffffffff 0200 0700 e8030000 0400 0500 ffffffff 1000 0700 ffffffff 2000 0500 ffffffff",
+			acl:  "user::rwx,user:1000:rwx,group::r-x,mask::rwx,other::r-x",
+		},
+		{
+			/* named entries are ordered by id within their tag */
+			name: "several named users",
+			blob: "0x02000000 0100 0600 ffffffff 0200 0600 e8030000 0200 0400 d0070000 0400 0000 ffffffff 1000 0600 ffffffff 2000 0000 ffffffff",
+			acl:  "user::rw-,user:1000:rw-,user:2000:r--,group::---,mask::rw-,other::---",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := mustHex(t, tt.blob)
+
+			acl, err := Decode(blob)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := formatACL(acl); got != tt.acl {
+				t.Errorf("Decode() = %s, want %s", got, tt.acl)
+			}
+
+			if got := acl.Encode(); !bytes.Equal(got, blob) {
+				t.Errorf("Encode() = %x, want %x", got, blob)
+			}
+		})
+	}
+}
+
+func TestEncodeOrdersEntries(t *testing.T) {
+	/* entries out of kernel order, base entries carrying a stray id */
+	acl := ACL{
+		{Tag: TagOther, Perm: PermRead, ID: 0},
+		{Tag: TagMask, Perm: PermRead | PermExecute, ID: undefinedID},
+		{Tag: TagGroup, Perm: PermRead | PermExecute, ID: 1001},
+		{Tag: TagUser, Perm: PermRead, ID: 1000},
+		{Tag: TagGroupObj, Perm: PermRead, ID: 7},
+		{Tag: TagUserObj, Perm: PermRead | PermWrite, ID: 0},
+	}
+
+	want := mustHex(t, "0x02000000 0100 0600 ffffffff 0200 0400 e8030000 0400 0400 ffffffff 0800 0500 e9030000 1000 0500 ffffffff 2000 0400 ffffffff")
+	if got := acl.Encode(); !bytes.Equal(got, want) {
+		t.Errorf("Encode() = %x, want %x", got, want)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		blob string
+	}{
+		{name: "too short", blob: "0x0200"},
+		{name: "version 1", blob: "0x01000000 0100 0600 ffffffff"},
+		{name: "big endian version", blob: "0x00000002 0100 0600 ffffffff"},
+		{name: "truncated entry", blob: "0x02000000 0100 0600 ffff"},
+		{name: "unknown tag", blob: "0x02000000 4000 0600 ffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(mustHex(t, tt.blob)); err == nil {
+				t.Errorf("Decode() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestRecalculateMask(t *testing.T) {
+	tests := []struct {
+		name string
+		acl  string
+		want string
+	}{
+		{
+			name: "minimal ACL gets no mask",
+			acl:  "user::rw-,group::r--,other::r--",
+			want: "user::rw-,group::r--,other::r--",
+		},
+		{
+			name: "mask added for named entries",
+			acl:  "user::rw-,user:1000:rw-,group::r--,other::---",
+			want: "user::rw-,user:1000:rw-,group::r--,mask::rw-,other::---",
+		},
+		{
+			name: "union of owning group and named entries",
+			acl:  "user::rwx,user:1000:r--,group::--x,group:1001:-w-,mask::---,other::r--",
+			want: "user::rwx,user:1000:r--,group::--x,group:1001:-w-,mask::rwx,other::r--",
+		},
+		{
+			name: "owner and other don't widen the mask",
+			acl:  "user::rwx,user:1000:r--,group::---,mask::rwx,other::rwx",
+			want: "user::rwx,user:1000:r--,group::---,mask::r--,other::rwx",
+		},
+		{
+			name: "existing mask kept without named entries",
+			acl:  "user::rw-,group::r-x,mask::---,other::---",
+			want: "user::rw-,group::r-x,mask::r-x,other::---",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatACL(mustACL(t, tt.acl).RecalculateMask()); got != tt.want {
+				t.Errorf("RecalculateMask() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePerm(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Perm
+		wantErr bool
+	}{
+		{in: "rwx", want: PermRead | PermWrite | PermExecute},
+		{in: "r-x", want: PermRead | PermExecute},
+		{in: "rw", want: PermRead | PermWrite},
+		{in: "---", want: 0},
+		{in: "6", want: PermRead | PermWrite},
+		{in: "0", want: 0},
+		{in: "rX", wantErr: true},
+		{in: "8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePerm(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePerm(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParsePerm(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package posixacl
+
+import (
+	"fmt"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* EffectiveRight is the permission an entry actually grants once the mask is applied */
+type EffectiveRight struct {
+	EntityType  string `json:"entityType"`
+	Entity      string `json:"entity"`
+	Permissions string `json:"permissions"`
+	Effective   string `json:"effective"`
+	IsDefault   bool   `json:"isDefault"`
+}
+
+/* returns the mask entry of an ACL, if it has one */
+func (a ACL) Mask() (Perm, bool) {
+	if idx, ok := a.Find(TagMask, undefinedID); ok {
+		return a[idx].Perm, true
+	}
+	return 0, false
+}
+
+/*
+returns the permissions an entry effectively grants
+the mask limits the owning group and all named entries, owner and other are never masked
+*/
+func (a ACL) Effective(entry Entry) Perm {
+	if entry.Tag != TagGroupObj && !entry.Tag.IsNamed() {
+		return entry.Perm
+	}
+
+	if mask, ok := a.Mask(); ok {
+		return entry.Perm & mask
+	}
+	return entry.Perm
+}
+
+/* lists effective rights of the owning group and all named entries of both ACLs */
+func (f *FileACL) EffectiveRights() []EffectiveRight {
+	rights := make([]EffectiveRight, 0)
+	rights = appendEffectiveRights(rights, f.Access, false)
+	rights = appendEffectiveRights(rights, f.Default, true)
+	return rights
+}
+
+/* appends effective rights of the masked entries of an ACL */
+func appendEffectiveRights(rights []EffectiveRight, acl ACL, isDefault bool) []EffectiveRight {
+	for _, entry := range acl.Sorted() {
+		if entry.Tag != TagGroupObj && !entry.Tag.IsNamed() {
+			continue
+		}
+
+		right := EffectiveRight{
+			EntityType:  entry.Tag.String(),
+			Permissions: entry.Perm.String(),
+			Effective:   acl.Effective(entry).String(),
+			IsDefault:   isDefault,
+		}
+
+		switch entry.Tag {
+		case TagUser:
+			right.Entity = UserName(entry.ID)
+		case TagGroup:
+			right.Entity = GroupName(entry.ID)
+		}
+
+		rights = append(rights, right)
+	}
+	return rights
+}
+
+/*
+lists warnings for applied entries whose effective permissions are narrower than requested
+only add/modify/set entries of the owning group and named users/groups can be limited by the mask
+*/
+func (f *FileACL) MaskWarnings(entries []types.ACLEntry) []string {
+	var warnings []string
+
+	for _, entry := range entries {
+		if entry.Action != "add" && entry.Action != "modify" && entry.Action != "set" {
+			continue
+		}
+
+		tag, id, err := resolveEntity(entry.EntityType, entry.Entity)
+		if err != nil || (tag != TagGroupObj && !tag.IsNamed()) {
+			continue
+		}
+
+		acl := f.Access
+		prefix := ""
+		if entry.IsDefault {
+			acl = f.Default
+			prefix = "default:"
+		}
+
+		/* compare against the stored entry so a conditional "X" is already resolved */
+		idx, ok := acl.Find(tag, id)
+		if !ok {
+			continue
+		}
+
+		requested := acl[idx].Perm
+		effective := acl.Effective(acl[idx])
+		if effective == requested {
+			continue
+		}
+
+		mask, _ := acl.Mask()
+		warnings = append(warnings, fmt.Sprintf(
+			"%s%s:%s requested %s but effective permissions are %s (mask %s)",
+			prefix, tag.String(), entry.Entity, requested.String(), effective.String(), mask.String(),
+		))
+	}
+
+	return warnings
+}
+
+/*
+builds a FileACL from rules as getfacl lists them (e.g. read through a daemon)
+named entities are resolved to uid/gid on this host
+*/
+func FromRules(rules []types.ACLRule, isDir bool) (*FileACL, error) {
+	facl := &FileACL{IsDir: isDir}
+
+	for _, rule := range rules {
+		tag, id, err := resolveEntity(rule.EntityType, rule.Entity)
+		if err != nil {
+			return nil, err
+		}
+
+		perm, err := ParsePerm(rule.Permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := Entry{Tag: tag, Perm: perm, ID: id}
+		if rule.IsDefault {
+			facl.Default = append(facl.Default, entry)
+		} else {
+			facl.Access = append(facl.Access, entry)
+		}
+	}
+
+	if len(facl.Access) == 0 {
+		return nil, fmt.Errorf("access ACL is empty")
+	}
+
+	/* only directories carry default ACLs */
+	if len(facl.Default) != 0 {
+		facl.IsDir = true
+	}
+
+	return facl, nil
+}
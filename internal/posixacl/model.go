@@ -0,0 +1,87 @@
+package posixacl
+
+/*
+	posixacl reads and writes POSIX ACLs directly through extended attributes
+	the kernel stores them as a little endian binary blob:
+	a 4 byte header (version) followed by 8 byte entries (tag, perm, id)
+*/
+
+/* extended attribute names used by the kernel for POSIX ACLs */
+const (
+	XattrAccess  = "system.posix_acl_access"
+	XattrDefault = "system.posix_acl_default"
+)
+
+/* version of the xattr representation understood by the kernel */
+const xattrVersion = 0x0002
+
+/* sizes of the binary header and of each entry */
+const (
+	headerSize = 4
+	entrySize  = 8
+)
+
+/* id stored for entries which don't refer to a named user or group */
+const undefinedID = ^uint32(0)
+
+/* Tag identifies the kind of an ACL entry */
+type Tag uint16
+
+/* defining tags (values match the kernel's ACL_* constants) */
+const (
+	TagUserObj  Tag = 0x01
+	TagUser     Tag = 0x02
+	TagGroupObj Tag = 0x04
+	TagGroup    Tag = 0x08
+	TagMask     Tag = 0x10
+	TagOther    Tag = 0x20
+)
+
+/* Perm holds read/write/execute bits of an ACL entry */
+type Perm uint16
+
+/* defining permission bits */
+const (
+	PermExecute Perm = 0x01
+	PermWrite   Perm = 0x02
+	PermRead    Perm = 0x04
+)
+
+/* Entry is a single rule of an ACL */
+type Entry struct {
+	Tag  Tag
+	Perm Perm
+
+	/* uid or gid for named entries, undefined for the rest */
+	ID uint32
+}
+
+/* ACL is a list of entries kept in the order required by the kernel */
+type ACL []Entry
+
+/* FileACL holds both ACLs of a file or directory */
+type FileACL struct {
+	Access  ACL
+	Default ACL
+
+	/* only directories can carry a default ACL */
+	IsDir bool
+
+	/* owner of the file, used when rendering entries */
+	UID uint32
+	GID uint32
+
+	/* track which ACLs were changed and whether their mask was given explicitly */
+	accessChanged  bool
+	defaultChanged bool
+	accessMaskSet  bool
+	defaultMaskSet bool
+
+	/* track which ACLs are being replaced as a whole by "set" entries */
+	accessReplaced  bool
+	defaultReplaced bool
+
+	/* whether the file was executable before any entry was applied (for "X") */
+	executable      bool
+	executableKnown bool
+}
@@ -0,0 +1,85 @@
+package posixacl
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+/*
+	name lookups go through NSS (passwd/group, LDAP via sssd, etc.)
+	results are cached since listing a directory resolves the same ids over and over
+*/
+
+var (
+	userNames  sync.Map
+	groupNames sync.Map
+)
+
+/* resolves a username (or numeric uid) to a uid */
+func LookupUID(name string) (uint32, error) {
+	if u, err := user.Lookup(name); err == nil {
+		return parseID(u.Uid)
+	}
+
+	/* setfacl also accepts numeric ids */
+	if id, err := parseID(name); err == nil {
+		return id, nil
+	}
+
+	return 0, fmt.Errorf("unknown user: %s", name)
+}
+
+/* resolves a group name (or numeric gid) to a gid */
+func LookupGID(name string) (uint32, error) {
+	if g, err := user.LookupGroup(name); err == nil {
+		return parseID(g.Gid)
+	}
+
+	/* setfacl also accepts numeric ids */
+	if id, err := parseID(name); err == nil {
+		return id, nil
+	}
+
+	return 0, fmt.Errorf("unknown group: %s", name)
+}
+
+/* resolves a uid to a username, falling back to the numeric id */
+func UserName(uid uint32) string {
+	if name, ok := userNames.Load(uid); ok {
+		return name.(string)
+	}
+
+	name := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+
+	userNames.Store(uid, name)
+	return name
+}
+
+/* resolves a gid to a group name, falling back to the numeric id */
+func GroupName(gid uint32) string {
+	if name, ok := groupNames.Load(gid); ok {
+		return name.(string)
+	}
+
+	name := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(name); err == nil {
+		name = g.Name
+	}
+
+	groupNames.Store(gid, name)
+	return name
+}
+
+/* parses a decimal uid/gid */
+func parseID(s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(id), nil
+}
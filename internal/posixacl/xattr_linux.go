@@ -0,0 +1,130 @@
+package posixacl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+/* reads access and default ACL of a path (symlinks are followed like getfacl does) */
+func Read(path string) (*FileACL, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return nil, &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+
+	facl := &FileACL{
+		IsDir: st.Mode&syscall.S_IFMT == syscall.S_IFDIR,
+		UID:   st.Uid,
+		GID:   st.Gid,
+	}
+
+	/* a missing access ACL is equivalent to the mode bits */
+	access, err := getxattr(path, XattrAccess)
+	switch {
+	case err == nil:
+		if facl.Access, err = Decode(access); err != nil {
+			return nil, fmt.Errorf("failed to decode access ACL of %s: %w", path, err)
+		}
+	case errors.Is(err, syscall.ENODATA):
+		facl.Access = FromMode(st.Mode)
+	default:
+		return nil, &os.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+
+	/* only directories can have a default ACL */
+	if facl.IsDir {
+		def, err := getxattr(path, XattrDefault)
+		switch {
+		case err == nil:
+			if facl.Default, err = Decode(def); err != nil {
+				return nil, fmt.Errorf("failed to decode default ACL of %s: %w", path, err)
+			}
+		case errors.Is(err, syscall.ENODATA):
+		default:
+			return nil, &os.PathError{Op: "getxattr", Path: path, Err: err}
+		}
+	}
+
+	return facl, nil
+}
+
+/*
+writes access and default ACL of a path
+the kernel updates the mode bits from the access ACL
+an empty default ACL removes the default ACL
+the default ACL is written first and put back if the access ACL can't be written,
+so a failed write leaves the path as it was
+*/
+func (f *FileACL) Write(path string) error {
+	if !f.IsDir {
+		if len(f.Default) != 0 {
+			return fmt.Errorf("default ACL can only be set on directories: %s", path)
+		}
+		if err := syscall.Setxattr(path, XattrAccess, f.Access.Encode(), 0); err != nil {
+			return &os.PathError{Op: "setxattr", Path: path, Err: err}
+		}
+		return nil
+	}
+
+	/* nil if the directory has no default ACL */
+	previous, err := getxattr(path, XattrDefault)
+	if err != nil && !errors.Is(err, syscall.ENODATA) {
+		return &os.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+
+	var def []byte
+	if len(f.Default) != 0 {
+		def = f.Default.Encode()
+	}
+	if err := writeDefault(path, def); err != nil {
+		return err
+	}
+
+	if err := syscall.Setxattr(path, XattrAccess, f.Access.Encode(), 0); err != nil {
+		writeErr := &os.PathError{Op: "setxattr", Path: path, Err: err}
+		if err := writeDefault(path, previous); err != nil {
+			return fmt.Errorf("%w; failed to put back default ACL: %w", writeErr, err)
+		}
+		return writeErr
+	}
+
+	return nil
+}
+
+/* sets the default ACL of a directory to an encoded ACL, removes it if nil */
+func writeDefault(path string, def []byte) error {
+	if def == nil {
+		if err := syscall.Removexattr(path, XattrDefault); err != nil && !errors.Is(err, syscall.ENODATA) {
+			return &os.PathError{Op: "removexattr", Path: path, Err: err}
+		}
+		return nil
+	}
+
+	if err := syscall.Setxattr(path, XattrDefault, def, 0); err != nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+/* reads an extended attribute, growing the buffer if it changed in between */
+func getxattr(path, attr string) ([]byte, error) {
+	for {
+		size, err := syscall.Getxattr(path, attr, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size)
+		n, err := syscall.Getxattr(path, attr, buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return buf[:n], nil
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package posixacl
+
+import "errors"
+
+/* POSIX ACL xattrs are only available on linux */
+var errUnsupported = errors.New("POSIX ACLs are only supported on linux")
+
+/* reads access and default ACL of a path */
+func Read(path string) (*FileACL, error) {
+	return nil, errUnsupported
+}
+
+/* writes access and default ACL of a path */
+func (f *FileACL) Write(path string) error {
+	return errUnsupported
+}
@@ -9,20 +9,71 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AclGrant struct {
+	ID             uuid.UUID          `json:"id"`
+	Username       string             `json:"username"`
+	TxnID          uuid.UUID          `json:"txn_id"`
+	TargetPath     string             `json:"target_path"`
+	EntityType     string             `json:"entity_type"`
+	Entity         string             `json:"entity"`
+	Permissions    string             `json:"permissions"`
+	IsDefault      bool               `json:"is_default"`
+	Recursive      bool               `json:"recursive"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	Status         string             `json:"status"`
+	RevokeTxnID    pgtype.UUID        `json:"revoke_txn_id"`
+	NotifiedAt     pgtype.Timestamptz `json:"notified_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	RevokeAttempts int32              `json:"revoke_attempts"`
+}
+
+type BackendInstance struct {
+	ID          uuid.UUID          `json:"id"`
+	HeartbeatAt pgtype.Timestamptz `json:"heartbeat_at"`
+}
+
+type DeferredTransaction struct {
+	ID           uuid.UUID          `json:"id"`
+	Username     string             `json:"username"`
+	ExecuteAt    pgtype.Timestamptz `json:"execute_at"`
+	Status       string             `json:"status"`
+	Transaction  []byte             `json:"transaction"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	DispatchedAt pgtype.Timestamptz `json:"dispatched_at"`
+}
+
 type PendingTransactionsArchive struct {
-	ID         uuid.UUID          `json:"id"`
-	SessionID  uuid.UUID          `json:"session_id"`
-	Timestamp  pgtype.Timestamptz `json:"timestamp"`
-	Operation  string             `json:"operation"`
-	TargetPath string             `json:"target_path"`
-	Entries    []byte             `json:"entries"`
-	Status     string             `json:"status"`
-	ErrorMsg   pgtype.Text        `json:"error_msg"`
-	Output     pgtype.Text        `json:"output"`
-	ExecutedBy string             `json:"executed_by"`
-	DurationMs pgtype.Int8        `json:"duration_ms"`
-	Execstatus bool               `json:"execstatus"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ID          uuid.UUID          `json:"id"`
+	SessionID   uuid.UUID          `json:"session_id"`
+	Timestamp   pgtype.Timestamptz `json:"timestamp"`
+	Operation   string             `json:"operation"`
+	TargetPath  string             `json:"target_path"`
+	Entries     []byte             `json:"entries"`
+	Status      string             `json:"status"`
+	ErrorMsg    pgtype.Text        `json:"error_msg"`
+	Output      pgtype.Text        `json:"output"`
+	ExecutedBy  string             `json:"executed_by"`
+	DurationMs  pgtype.Int8        `json:"duration_ms"`
+	Execstatus  bool               `json:"execstatus"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	SourcePath  pgtype.Text        `json:"source_path"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	Steps       []byte             `json:"steps"`
+	Recursive   bool               `json:"recursive"`
+	Acl         []byte             `json:"acl"`
+	ExpectedAcl []byte             `json:"expected_acl"`
+	Priority    string             `json:"priority"`
+}
+
+type QueuedTransaction struct {
+	ID          uuid.UUID          `json:"id"`
+	Username    string             `json:"username"`
+	Owner       uuid.UUID          `json:"owner"`
+	Status      string             `json:"status"`
+	Transaction []byte             `json:"transaction"`
+	EnqueuedAt  pgtype.Timestamptz `json:"enqueued_at"`
+	ClaimedAt   pgtype.Timestamptz `json:"claimed_at"`
 }
 
 type ResultsTransactionsArchive struct {
@@ -39,6 +90,13 @@ type ResultsTransactionsArchive struct {
 	DurationMs pgtype.Int8        `json:"duration_ms"`
 	Execstatus bool               `json:"execstatus"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	Acl        []byte             `json:"acl"`
+	BeforeAcl  []byte             `json:"before_acl"`
+	SourcePath pgtype.Text        `json:"source_path"`
+	Attempts   int32              `json:"attempts"`
+	LastError  pgtype.Text        `json:"last_error"`
+	Steps      []byte             `json:"steps"`
+	Priority   string             `json:"priority"`
 }
 
 type SessionsArchive struct {
@@ -59,25 +59,51 @@ INSERT INTO pending_transactions_archive (
     output,
     executed_by,
     duration_ms,
-    ExecStatus
+    ExecStatus,
+    source_path,
+    attempts,
+    last_error,
+    steps,
+    recursive,
+    acl,
+    expected_acl,
+    priority
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
-) RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+)
+ON CONFLICT (id) DO UPDATE SET
+    session_id = EXCLUDED.session_id,
+    status = EXCLUDED.status,
+    error_msg = EXCLUDED.error_msg,
+    output = EXCLUDED.output,
+    duration_ms = EXCLUDED.duration_ms,
+    ExecStatus = EXCLUDED.ExecStatus,
+    attempts = EXCLUDED.attempts,
+    last_error = EXCLUDED.last_error
+RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority
 `
 
 type CreatePendingTransactionPQParams struct {
-	ID         uuid.UUID          `json:"id"`
-	SessionID  uuid.UUID          `json:"session_id"`
-	Timestamp  pgtype.Timestamptz `json:"timestamp"`
-	Operation  string             `json:"operation"`
-	TargetPath string             `json:"target_path"`
-	Entries    []byte             `json:"entries"`
-	Status     string             `json:"status"`
-	ErrorMsg   pgtype.Text        `json:"error_msg"`
-	Output     pgtype.Text        `json:"output"`
-	ExecutedBy string             `json:"executed_by"`
-	DurationMs pgtype.Int8        `json:"duration_ms"`
-	Execstatus bool               `json:"execstatus"`
+	ID          uuid.UUID          `json:"id"`
+	SessionID   uuid.UUID          `json:"session_id"`
+	Timestamp   pgtype.Timestamptz `json:"timestamp"`
+	Operation   string             `json:"operation"`
+	TargetPath  string             `json:"target_path"`
+	Entries     []byte             `json:"entries"`
+	Status      string             `json:"status"`
+	ErrorMsg    pgtype.Text        `json:"error_msg"`
+	Output      pgtype.Text        `json:"output"`
+	ExecutedBy  string             `json:"executed_by"`
+	DurationMs  pgtype.Int8        `json:"duration_ms"`
+	Execstatus  bool               `json:"execstatus"`
+	SourcePath  pgtype.Text        `json:"source_path"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	Steps       []byte             `json:"steps"`
+	Recursive   bool               `json:"recursive"`
+	Acl         []byte             `json:"acl"`
+	ExpectedAcl []byte             `json:"expected_acl"`
+	Priority    string             `json:"priority"`
 }
 
 func (q *Queries) CreatePendingTransactionPQ(ctx context.Context, arg CreatePendingTransactionPQParams) (PendingTransactionsArchive, error) {
@@ -94,6 +120,14 @@ func (q *Queries) CreatePendingTransactionPQ(ctx context.Context, arg CreatePend
 		arg.ExecutedBy,
 		arg.DurationMs,
 		arg.Execstatus,
+		arg.SourcePath,
+		arg.Attempts,
+		arg.LastError,
+		arg.Steps,
+		arg.Recursive,
+		arg.Acl,
+		arg.ExpectedAcl,
+		arg.Priority,
 	)
 	var i PendingTransactionsArchive
 	err := row.Scan(
@@ -110,6 +144,14 @@ func (q *Queries) CreatePendingTransactionPQ(ctx context.Context, arg CreatePend
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Recursive,
+		&i.Acl,
+		&i.ExpectedAcl,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -135,7 +177,7 @@ func (q *Queries) DeletePendingTransactionsBySessionPQ(ctx context.Context, sess
 }
 
 const getPendingTransactionPQ = `-- name: GetPendingTransactionPQ :one
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE id = $1
 `
 
@@ -156,6 +198,14 @@ func (q *Queries) GetPendingTransactionPQ(ctx context.Context, id uuid.UUID) (Pe
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Recursive,
+		&i.Acl,
+		&i.ExpectedAcl,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -182,8 +232,33 @@ func (q *Queries) GetPendingTransactionStatsPQ(ctx context.Context, sessionID uu
 	return i, err
 }
 
+const getPendingTransactionUsersPQ = `-- name: GetPendingTransactionUsersPQ :many
+SELECT DISTINCT executed_by FROM pending_transactions_archive
+WHERE status = 'pending'
+`
+
+func (q *Queries) GetPendingTransactionUsersPQ(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, getPendingTransactionUsersPQ)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var executed_by string
+		if err := rows.Scan(&executed_by); err != nil {
+			return nil, err
+		}
+		items = append(items, executed_by)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPendingTransactionsByOperationPQ = `-- name: GetPendingTransactionsByOperationPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE session_id = $1 AND operation = $2
 ORDER BY timestamp DESC
 `
@@ -216,6 +291,14 @@ func (q *Queries) GetPendingTransactionsByOperationPQ(ctx context.Context, arg G
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -228,7 +311,7 @@ func (q *Queries) GetPendingTransactionsByOperationPQ(ctx context.Context, arg G
 }
 
 const getPendingTransactionsByPathPQ = `-- name: GetPendingTransactionsByPathPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE session_id = $1 AND target_path = $2
 ORDER BY timestamp DESC
 `
@@ -261,6 +344,14 @@ func (q *Queries) GetPendingTransactionsByPathPQ(ctx context.Context, arg GetPen
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -273,7 +364,7 @@ func (q *Queries) GetPendingTransactionsByPathPQ(ctx context.Context, arg GetPen
 }
 
 const getPendingTransactionsBySessionPQ = `-- name: GetPendingTransactionsBySessionPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE session_id = $1
 ORDER BY timestamp DESC
 `
@@ -301,6 +392,62 @@ func (q *Queries) GetPendingTransactionsBySessionPQ(ctx context.Context, session
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingTransactionsByUserPQ = `-- name: GetPendingTransactionsByUserPQ :many
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
+WHERE executed_by = $1 AND status = 'pending'
+ORDER BY timestamp ASC
+`
+
+func (q *Queries) GetPendingTransactionsByUserPQ(ctx context.Context, executedBy string) ([]PendingTransactionsArchive, error) {
+	rows, err := q.db.Query(ctx, getPendingTransactionsByUserPQ, executedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PendingTransactionsArchive{}
+	for rows.Next() {
+		var i PendingTransactionsArchive
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Timestamp,
+			&i.Operation,
+			&i.TargetPath,
+			&i.Entries,
+			&i.Status,
+			&i.ErrorMsg,
+			&i.Output,
+			&i.ExecutedBy,
+			&i.DurationMs,
+			&i.Execstatus,
+			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -313,7 +460,7 @@ func (q *Queries) GetPendingTransactionsBySessionPQ(ctx context.Context, session
 }
 
 const getPendingTransactionsByUserPaginatedPQ = `-- name: GetPendingTransactionsByUserPaginatedPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE executed_by = $1
 ORDER BY timestamp DESC
 LIMIT $2 OFFSET $3
@@ -348,6 +495,14 @@ func (q *Queries) GetPendingTransactionsByUserPaginatedPQ(ctx context.Context, a
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -360,7 +515,7 @@ func (q *Queries) GetPendingTransactionsByUserPaginatedPQ(ctx context.Context, a
 }
 
 const getPendingTransactionsPQ = `-- name: GetPendingTransactionsPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM pending_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority FROM pending_transactions_archive
 WHERE session_id = $1 AND status = 'pending'
 ORDER BY timestamp DESC
 `
@@ -388,6 +543,14 @@ func (q *Queries) GetPendingTransactionsPQ(ctx context.Context, sessionID uuid.U
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Recursive,
+			&i.Acl,
+			&i.ExpectedAcl,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -408,7 +571,7 @@ SET
     duration_ms = $5,
     ExecStatus = $6
 WHERE id = $1
-RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at
+RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, source_path, attempts, last_error, steps, recursive, acl, expected_acl, priority
 `
 
 type UpdatePendingTransactionStatusPQParams struct {
@@ -444,6 +607,14 @@ func (q *Queries) UpdatePendingTransactionStatusPQ(ctx context.Context, arg Upda
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Recursive,
+		&i.Acl,
+		&i.ExpectedAcl,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -8,13 +8,22 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AckQueuedTransactionPQ(ctx context.Context, id uuid.UUID) error
+	CancelDeferredTransactionPQ(ctx context.Context, arg CancelDeferredTransactionPQParams) (DeferredTransaction, error)
+	ClaimDueDeferredTransactionsPQ(ctx context.Context, arg ClaimDueDeferredTransactionsPQParams) ([]DeferredTransaction, error)
+	ClaimExpiredGrantsPQ(ctx context.Context, limit int32) ([]AclGrant, error)
+	ClaimGrantsNearingExpiryPQ(ctx context.Context, arg ClaimGrantsNearingExpiryPQParams) ([]AclGrant, error)
+	ClaimQueuedTransactionPQ(ctx context.Context, id uuid.UUID) error
 	CountPendingTransactionsByOperationPQ(ctx context.Context, arg CountPendingTransactionsByOperationPQParams) (int64, error)
 	CountPendingTransactionsByStatusPQ(ctx context.Context, arg CountPendingTransactionsByStatusPQParams) (int64, error)
 	CountResultsTransactionsByOperationPQ(ctx context.Context, arg CountResultsTransactionsByOperationPQParams) (int64, error)
 	CountResultsTransactionsByStatusPQ(ctx context.Context, arg CountResultsTransactionsByStatusPQParams) (int64, error)
+	CreateDeferredTransactionPQ(ctx context.Context, arg CreateDeferredTransactionPQParams) (DeferredTransaction, error)
+	CreateGrantPQ(ctx context.Context, arg CreateGrantPQParams) (AclGrant, error)
 	CreatePendingTransactionPQ(ctx context.Context, arg CreatePendingTransactionPQParams) (PendingTransactionsArchive, error)
 	CreateResultsTransactionPQ(ctx context.Context, arg CreateResultsTransactionPQParams) (ResultsTransactionsArchive, error)
 	DeletePendingTransactionPQ(ctx context.Context, id uuid.UUID) error
@@ -22,12 +31,18 @@ type Querier interface {
 	DeleteResultsTransactionPQ(ctx context.Context, id uuid.UUID) error
 	DeleteResultsTransactionsBySessionPQ(ctx context.Context, sessionID uuid.UUID) error
 	DeleteSessionPQ(ctx context.Context, id uuid.UUID) error
+	EnqueueTransactionPQ(ctx context.Context, arg EnqueueTransactionPQParams) error
+	ExtendGrantPQ(ctx context.Context, arg ExtendGrantPQParams) (AclGrant, error)
+	GetActiveGrantsByUserPQ(ctx context.Context, username string) ([]AclGrant, error)
+	GetDeferredTransactionPQ(ctx context.Context, id uuid.UUID) (DeferredTransaction, error)
 	GetFailedResultsTransactionsPQ(ctx context.Context, sessionID uuid.UUID) ([]ResultsTransactionsArchive, error)
 	GetPendingTransactionPQ(ctx context.Context, id uuid.UUID) (PendingTransactionsArchive, error)
 	GetPendingTransactionStatsPQ(ctx context.Context, sessionID uuid.UUID) (GetPendingTransactionStatsPQRow, error)
+	GetPendingTransactionUsersPQ(ctx context.Context) ([]string, error)
 	GetPendingTransactionsByOperationPQ(ctx context.Context, arg GetPendingTransactionsByOperationPQParams) ([]PendingTransactionsArchive, error)
 	GetPendingTransactionsByPathPQ(ctx context.Context, arg GetPendingTransactionsByPathPQParams) ([]PendingTransactionsArchive, error)
 	GetPendingTransactionsBySessionPQ(ctx context.Context, sessionID uuid.UUID) ([]PendingTransactionsArchive, error)
+	GetPendingTransactionsByUserPQ(ctx context.Context, executedBy string) ([]PendingTransactionsArchive, error)
 	GetPendingTransactionsByUserPaginatedPQ(ctx context.Context, arg GetPendingTransactionsByUserPaginatedPQParams) ([]PendingTransactionsArchive, error)
 	GetPendingTransactionsPQ(ctx context.Context, sessionID uuid.UUID) ([]PendingTransactionsArchive, error)
 	GetResultsTransactionPQ(ctx context.Context, id uuid.UUID) (ResultsTransactionsArchive, error)
@@ -36,10 +51,21 @@ type Querier interface {
 	GetResultsTransactionsByPathPQ(ctx context.Context, arg GetResultsTransactionsByPathPQParams) ([]ResultsTransactionsArchive, error)
 	GetResultsTransactionsBySessionPQ(ctx context.Context, sessionID uuid.UUID) ([]ResultsTransactionsArchive, error)
 	GetResultsTransactionsByUserPaginatedPQ(ctx context.Context, arg GetResultsTransactionsByUserPaginatedPQParams) ([]ResultsTransactionsArchive, error)
+	GetScheduledDeferredTransactionsByUserPQ(ctx context.Context, username string) ([]DeferredTransaction, error)
 	GetSessionByUsernamePaginatedPQ(ctx context.Context, arg GetSessionByUsernamePaginatedPQParams) ([]SessionsArchive, error)
 	GetSessionPQ(ctx context.Context, id uuid.UUID) (SessionsArchive, error)
 	GetSuccessfulResultsTransactionsPQ(ctx context.Context, sessionID uuid.UUID) ([]ResultsTransactionsArchive, error)
+	HeartbeatInstancePQ(ctx context.Context, id uuid.UUID) error
+	PruneInstancesPQ(ctx context.Context, heartbeatAt pgtype.Timestamptz) error
+	ReactivateGrantPQ(ctx context.Context, id uuid.UUID) error
+	RearmGrantPQ(ctx context.Context, revokeTxnID pgtype.UUID) (AclGrant, error)
+	ReclaimQueuedTransactionsPQ(ctx context.Context, arg ReclaimQueuedTransactionsPQParams) ([]QueuedTransaction, error)
+	ReleaseInstancePQ(ctx context.Context, id uuid.UUID) error
+	ResetDeferredTransactionPQ(ctx context.Context, id uuid.UUID) error
+	RevokeGrantPQ(ctx context.Context, arg RevokeGrantPQParams) (AclGrant, error)
+	SetGrantRevokeTxnPQ(ctx context.Context, arg SetGrantRevokeTxnPQParams) error
 	StoreSessionPQ(ctx context.Context, arg StoreSessionPQParams) (SessionsArchive, error)
+	SupersedeGrantsPQ(ctx context.Context, arg SupersedeGrantsPQParams) error
 	UpdatePendingTransactionStatusPQ(ctx context.Context, arg UpdatePendingTransactionStatusPQParams) (PendingTransactionsArchive, error)
 	UpdateResultsTransactionStatusPQ(ctx context.Context, arg UpdateResultsTransactionStatusPQParams) (ResultsTransactionsArchive, error)
 }
@@ -59,10 +59,17 @@ INSERT INTO results_transactions_archive (
     output,
     executed_by,
     duration_ms,
-    ExecStatus
+    ExecStatus,
+    acl,
+    before_acl,
+    source_path,
+    attempts,
+    last_error,
+    steps,
+    priority
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
-) RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+) RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority
 `
 
 type CreateResultsTransactionPQParams struct {
@@ -78,6 +85,13 @@ type CreateResultsTransactionPQParams struct {
 	ExecutedBy string             `json:"executed_by"`
 	DurationMs pgtype.Int8        `json:"duration_ms"`
 	Execstatus bool               `json:"execstatus"`
+	Acl        []byte             `json:"acl"`
+	BeforeAcl  []byte             `json:"before_acl"`
+	SourcePath pgtype.Text        `json:"source_path"`
+	Attempts   int32              `json:"attempts"`
+	LastError  pgtype.Text        `json:"last_error"`
+	Steps      []byte             `json:"steps"`
+	Priority   string             `json:"priority"`
 }
 
 func (q *Queries) CreateResultsTransactionPQ(ctx context.Context, arg CreateResultsTransactionPQParams) (ResultsTransactionsArchive, error) {
@@ -94,6 +108,13 @@ func (q *Queries) CreateResultsTransactionPQ(ctx context.Context, arg CreateResu
 		arg.ExecutedBy,
 		arg.DurationMs,
 		arg.Execstatus,
+		arg.Acl,
+		arg.BeforeAcl,
+		arg.SourcePath,
+		arg.Attempts,
+		arg.LastError,
+		arg.Steps,
+		arg.Priority,
 	)
 	var i ResultsTransactionsArchive
 	err := row.Scan(
@@ -110,6 +131,13 @@ func (q *Queries) CreateResultsTransactionPQ(ctx context.Context, arg CreateResu
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.Acl,
+		&i.BeforeAcl,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -135,7 +163,7 @@ func (q *Queries) DeleteResultsTransactionsBySessionPQ(ctx context.Context, sess
 }
 
 const getFailedResultsTransactionsPQ = `-- name: GetFailedResultsTransactionsPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE session_id = $1 AND status = 'failed'
 ORDER BY timestamp DESC
 `
@@ -163,6 +191,13 @@ func (q *Queries) GetFailedResultsTransactionsPQ(ctx context.Context, sessionID
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -175,7 +210,7 @@ func (q *Queries) GetFailedResultsTransactionsPQ(ctx context.Context, sessionID
 }
 
 const getResultsTransactionPQ = `-- name: GetResultsTransactionPQ :one
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE id = $1
 `
 
@@ -196,6 +231,13 @@ func (q *Queries) GetResultsTransactionPQ(ctx context.Context, id uuid.UUID) (Re
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.Acl,
+		&i.BeforeAcl,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -230,7 +272,7 @@ func (q *Queries) GetResultsTransactionStatsPQ(ctx context.Context, sessionID uu
 }
 
 const getResultsTransactionsByOperationPQ = `-- name: GetResultsTransactionsByOperationPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE session_id = $1 AND operation = $2
 ORDER BY timestamp DESC
 `
@@ -263,6 +305,13 @@ func (q *Queries) GetResultsTransactionsByOperationPQ(ctx context.Context, arg G
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -275,7 +324,7 @@ func (q *Queries) GetResultsTransactionsByOperationPQ(ctx context.Context, arg G
 }
 
 const getResultsTransactionsByPathPQ = `-- name: GetResultsTransactionsByPathPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE session_id = $1 AND target_path = $2
 ORDER BY timestamp DESC
 `
@@ -308,6 +357,13 @@ func (q *Queries) GetResultsTransactionsByPathPQ(ctx context.Context, arg GetRes
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -320,7 +376,7 @@ func (q *Queries) GetResultsTransactionsByPathPQ(ctx context.Context, arg GetRes
 }
 
 const getResultsTransactionsBySessionPQ = `-- name: GetResultsTransactionsBySessionPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE session_id = $1
 ORDER BY timestamp DESC
 `
@@ -348,6 +404,13 @@ func (q *Queries) GetResultsTransactionsBySessionPQ(ctx context.Context, session
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -360,7 +423,7 @@ func (q *Queries) GetResultsTransactionsBySessionPQ(ctx context.Context, session
 }
 
 const getResultsTransactionsByUserPaginatedPQ = `-- name: GetResultsTransactionsByUserPaginatedPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE executed_by = $1
 ORDER BY timestamp DESC
 LIMIT $2 OFFSET $3
@@ -395,6 +458,13 @@ func (q *Queries) GetResultsTransactionsByUserPaginatedPQ(ctx context.Context, a
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -407,7 +477,7 @@ func (q *Queries) GetResultsTransactionsByUserPaginatedPQ(ctx context.Context, a
 }
 
 const getSuccessfulResultsTransactionsPQ = `-- name: GetSuccessfulResultsTransactionsPQ :many
-SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at FROM results_transactions_archive
+SELECT id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority FROM results_transactions_archive
 WHERE session_id = $1 AND status = 'success'
 ORDER BY timestamp DESC
 `
@@ -435,6 +505,13 @@ func (q *Queries) GetSuccessfulResultsTransactionsPQ(ctx context.Context, sessio
 			&i.DurationMs,
 			&i.Execstatus,
 			&i.CreatedAt,
+			&i.Acl,
+			&i.BeforeAcl,
+			&i.SourcePath,
+			&i.Attempts,
+			&i.LastError,
+			&i.Steps,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -455,7 +532,7 @@ SET
     duration_ms = $5,
     ExecStatus = $6
 WHERE id = $1
-RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at
+RETURNING id, session_id, timestamp, operation, target_path, entries, status, error_msg, output, executed_by, duration_ms, execstatus, created_at, acl, before_acl, source_path, attempts, last_error, steps, priority
 `
 
 type UpdateResultsTransactionStatusPQParams struct {
@@ -491,6 +568,13 @@ func (q *Queries) UpdateResultsTransactionStatusPQ(ctx context.Context, arg Upda
 		&i.DurationMs,
 		&i.Execstatus,
 		&i.CreatedAt,
+		&i.Acl,
+		&i.BeforeAcl,
+		&i.SourcePath,
+		&i.Attempts,
+		&i.LastError,
+		&i.Steps,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -12,9 +12,11 @@ import (
 type RedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
 	HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd
+	HGet(ctx context.Context, key, field string) *redis.StringCmd
 	HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd
 	RPush(ctx context.Context, key string, value interface{}) *redis.IntCmd
 	LRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd
@@ -22,6 +24,7 @@ type RedisClient interface {
 	HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd
 	FlushAll(ctx context.Context) error
 	HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
 }
 
 /* redisClient implementation */
@@ -57,6 +60,11 @@ func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
+/* sets a key-value pair only if the key doesn't exist yet */
+func (r *redisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 /* deletes a redis entry */
 func (r *redisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	return r.client.Del(ctx, keys...)
@@ -82,6 +90,11 @@ func (r *redisClient) HSet(ctx context.Context, key string, values ...interface{
 	return r.client.HSet(ctx, key, values...)
 }
 
+/* hash get a single field for redis */
+func (r *redisClient) HGet(ctx context.Context, key, field string) *redis.StringCmd {
+	return r.client.HGet(ctx, key, field)
+}
+
 /* hash delete for redis */
 func (r *redisClient) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
 	return r.client.HDel(ctx, key, fields...)
@@ -111,3 +124,8 @@ func (r *redisClient) FlushAll(ctx context.Context) error {
 func (r *redisClient) HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd {
 	return r.client.HIncrBy(ctx, key, field, incr)
 }
+
+/* publish a message to a redis channel */
+func (r *redisClient) Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	return r.client.Publish(ctx, channel, message)
+}
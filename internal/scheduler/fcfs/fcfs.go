@@ -2,129 +2,95 @@ package fcfs
 
 import (
 	"context"
-	"runtime"
-	"time"
 
-	"go.uber.org/zap"
-
-	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
 )
 
 /* spawns a new FCFS scheduler */
-func NewFCFSScheduler(sm *session.Manager, processor transprocessor.TransactionProcessor) *FCFSScheduler {
-	/* calculate max workers */
-	maxProcs := runtime.GOMAXPROCS(0)
-	maxWorkers := config.BackendConfig.AppInfo.MaxWorkers
-
-	/*
-		incase of maxWorkers set less than or equal to 0,
-		use 75% of GOMAXPROCS to prevent starvation to other processes
-	*/
-	if maxWorkers <= 0 {
-		maxWorkers = int(float64(maxProcs) * 0.75)
-	}
-
-	/* Prevent over-allocation */
-	if maxWorkers > maxProcs {
-		maxWorkers = maxProcs
-	}
+func NewFCFSScheduler(sm *session.Manager, processor transprocessor.TransactionProcessor, watchdog *scheduler.Watchdog) *FCFSScheduler {
+	maxWorkers := scheduler.MaxWorkers()
 
 	return &FCFSScheduler{
 		curSessionManager: sm,
 		maxWorkers:        maxWorkers,
 		semaphore:         make(chan struct{}, maxWorkers),
 		processor:         processor,
+		watchdog:          watchdog,
 	}
 }
 
-/* run the fcfs scheduler with context */
+/*
+run the fcfs scheduler with context
+sessions are visited in round robin order, one transaction per session at a time
+once a whole round finds nothing ready, the scheduler sleeps until the session manager signals new work
+*/
 func (f *FCFSScheduler) Run(ctx context.Context) error {
 	for {
+		/* check if ctx is done before every round */
 		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		/* RULE: ctx is propagated all over the coming functions */
+
+		if f.dispatchRound(ctx) {
+			continue
+		}
 
-		/* check if ctx is done - catchable if default is not working hard (ideal scheduler) */
+		/* no work; a signal sent during the round is still buffered, so nothing is missed */
+		select {
 		case <-ctx.Done():
 			return nil
+		case <-f.curSessionManager.Wakeup():
+		}
+	}
+}
 
-		/* in case default is working hard - ctx is passed here so it must attempt to quit */
-		default:
-			/* RULE: ctx is propagated all over the coming functions */
-
-			/* get next session in the queue (round robin manner) */
-			curSession := f.curSessionManager.GetNextSession()
-			if curSession == nil {
-				/* might need a delay of 10 ms */
-				continue
-			}
-
-			/* check if transaction queue of the session is empty */
-			curSession.Mutex.Lock()
-			if curSession.TransactionQueue.Len() == 0 {
-				curSession.Mutex.Unlock()
-				continue
-			}
-
-			/* get a transaction from the session to process */
-			transaction := curSession.TransactionQueue.Remove(curSession.TransactionQueue.Front()).(*types.Transaction)
-			curSession.Mutex.Unlock()
-
-			/* block if all workers are busy */
-			f.semaphore <- struct{}{}
-
-			/* go routine is available to be spawned */
-			go func(curSession *session.Session, transaction *types.Transaction) {
-				/* defer clearing the semaphore channel */
-				defer func() { <-f.semaphore }()
-
-				/*
-					process the transaction
-					* processTransaction handles transaction processing completely
-					* now it is responsible now responsible to execute it
-					* role of scheduler in handling transactions ends here
-				*/
-				if err := f.processor.Process(ctx, curSession, transaction); err != nil {
-					zap.L().Error("Failed to process transaction",
-						zap.Error(err),
-					)
-				}
-
-				/* we assume the transaction has been processed -> updated Redis */
-				transaction.Status = types.StatusSuccess
-
-				/* update duration of transaction execution */
-				elapsed := time.Since(transaction.Timestamp)
-				transaction.DurationMs = elapsed.Milliseconds()
-
-				/* this whole code snippet should be called "Update Session State after transaction execution" */
-				/* update the session's completed/failed count */
-				curSession.Mutex.Lock()
-				if transaction.ExecStatus {
-					curSession.CompletedCount++
-					if err := f.curSessionManager.IncrementSessionCompletedRedis(curSession); err != nil {
-						zap.L().Error("Failed to increment completed session in Redis")
-					}
-				} else {
-					curSession.FailedCount++
-					if err := f.curSessionManager.IncrementSessionFailedRedis(curSession); err != nil {
-						zap.L().Error("Failed to increment failed session in Redis")
-					}
-				}
-				curSession.Mutex.Unlock()
-
-				/* store the result of processed transaction into Redis */
-				if err := f.curSessionManager.SaveTransactionRedisList(curSession, transaction, "txresults"); err != nil {
-					zap.L().Error("Failed to store processed transaction into Redis")
-				}
-
-				/* remove the transaction as pending from Redis */
-				if err := f.curSessionManager.RemovePendingTransaction(curSession, transaction.ID); err != nil {
-					zap.L().Error("Failed to remove pending transaction from Redis")
-				}
-
-			}(curSession, transaction)
+/* visits every session once and dispatches its next ready transaction, reports if anything was dispatched */
+func (f *FCFSScheduler) dispatchRound(ctx context.Context) bool {
+	dispatched := false
+
+	/* higher lanes are drained first, sessions without work in this lane are passed over */
+	lane, ok := f.curSessionManager.ReadyLane()
+	if !ok {
+		return false
+	}
+
+	for i := f.curSessionManager.SessionCount(); i > 0; i-- {
+		/* get next session in the queue (round robin manner) */
+		curSession := f.curSessionManager.GetNextSession()
+		if curSession == nil {
+			break
+		}
+
+		/* get a transaction from the session to process, skipping those waiting on older ones for the same path */
+		transaction := f.curSessionManager.PopReadyTransaction(curSession, lane)
+		if transaction == nil {
+			continue
 		}
+
+		dispatched = true
+		f.dispatch(ctx, curSession, transaction)
 	}
+
+	return dispatched
+}
+
+/* hands a transaction to a worker, blocks while all workers are busy */
+func (f *FCFSScheduler) dispatch(ctx context.Context, curSession *session.Session, transaction *types.Transaction) {
+	/* block if all workers are busy */
+	f.semaphore <- struct{}{}
+
+	/* go routine is available to be spawned */
+	go func(curSession *session.Session, transaction *types.Transaction) {
+		/* defer clearing the semaphore channel */
+		defer func() { <-f.semaphore }()
+
+		scheduler.ExecuteTransaction(ctx, f.curSessionManager, f.processor, f.watchdog, curSession, transaction)
+	}(curSession, transaction)
 }
@@ -1,6 +1,7 @@
 package fcfs
 
 import (
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
 )
@@ -19,4 +20,7 @@ type FCFSScheduler struct {
 	/* for limiting spawning of goroutines */
 	semaphore chan struct{}
 	processor transprocessor.TransactionProcessor
+
+	/* tracks transactions handed to workers */
+	watchdog *scheduler.Watchdog
 }
@@ -1,5 +1,75 @@
 package scheduler
 
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/api/middleware"
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+)
+
 /* contains handlers related to monitoring the scheduler */
 
-/* TODO: Implementing a watchdog */
+/* resolves the LDAP groups of a user, used for admin roles */
+type GroupResolver interface {
+	UserGroups(username string) ([]string, error)
+}
+
+/*
+admin handler reporting the state of the scheduler
+queue depth per session, busy and free workers, in-flight transactions and the oldest pending transaction
+only members of watchdog.admin_roles may use it
+*/
+func IntrospectionHandler(watchdog *Watchdog, sm *session.Manager, groups GroupResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		/* extract username from JWT Token */
+		username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+		if !ok {
+			http.Error(w, "Invalid user context", http.StatusInternalServerError)
+			return
+		}
+
+		userGroups, err := groups.UserGroups(username)
+		if err != nil {
+			zap.L().Error("Failed to get groups of user",
+				zap.String("user", username),
+				zap.Error(err),
+			)
+			http.Error(w, "Failed to authorize user", http.StatusBadGateway)
+			return
+		}
+
+		admin := false
+		for _, group := range userGroups {
+			if slices.Contains(config.BackendConfig.Watchdog.AdminRoles, group) {
+				admin = true
+				break
+			}
+		}
+		if !admin {
+			http.Error(w, "Scheduler introspection not allowed for user", http.StatusForbidden)
+			return
+		}
+
+		workers, inFlight := watchdog.Stats()
+		sessions, oldest := sm.QueueStats()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"timestamp":     time.Now(),
+			"scheduler":     config.BackendConfig.AppInfo.Scheduler,
+			"workers":       workers,
+			"inFlight":      inFlight,
+			"sessions":      sessions,
+			"oldestPending": oldest,
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
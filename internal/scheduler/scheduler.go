@@ -1,7 +1,7 @@
 package scheduler
 
 /*
-	laclm uses FCFS scheduling algorithm
+	laclm uses FCFS scheduling algorithm by default, weighted fair queuing (wfq) is selected with app.scheduler
 	the scheduler module is highly modular and can be attached/detached/replaced quickly on-demand
 */
 
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	the watchdog tracks every transaction handed to a worker, with the worker slot and the time it started
+	transactions running past the deadline are flagged (logged once) or cancelled through their context
+	it is shared by all schedulers and backs the scheduler introspection endpoint
+*/
+
+/* cause of the context of a transaction cancelled by the watchdog */
+var ErrWatchdogDeadline = errors.New("transaction exceeded the watchdog deadline")
+
+/* transaction being processed by a worker */
+type inFlight struct {
+	worker    int
+	txnID     uuid.UUID
+	sessionID uuid.UUID
+	username  string
+	operation types.OperationType
+	target    string
+	startedAt time.Time
+	flagged   bool
+	cancel    context.CancelCauseFunc
+}
+
+/* watchdog of in-flight transactions */
+type Watchdog struct {
+	mutex      sync.Mutex
+	maxWorkers int
+
+	/* worker slots not in use, the lowest slot is taken first */
+	freeWorkers []int
+
+	inFlight map[uuid.UUID]*inFlight
+}
+
+/* in-flight transaction, reported by the scheduler introspection endpoint */
+type InFlightStats struct {
+	Worker     int                 `json:"worker"`
+	ID         string              `json:"id"`
+	SessionID  string              `json:"sessionId"`
+	Username   string              `json:"username"`
+	Operation  types.OperationType `json:"operation"`
+	TargetPath string              `json:"targetPath"`
+	StartedAt  time.Time           `json:"startedAt"`
+	RunningMs  int64               `json:"runningMs"`
+	Flagged    bool                `json:"flagged"`
+}
+
+/* usage of the worker slots */
+type WorkerStats struct {
+	Max  int `json:"max"`
+	Busy int `json:"busy"`
+	Free int `json:"free"`
+}
+
+/* create a new watchdog for a number of workers */
+func NewWatchdog(maxWorkers int) *Watchdog {
+	freeWorkers := make([]int, 0, maxWorkers)
+	for worker := maxWorkers; worker > 0; worker-- {
+		freeWorkers = append(freeWorkers, worker)
+	}
+
+	return &Watchdog{
+		maxWorkers:  maxWorkers,
+		freeWorkers: freeWorkers,
+		inFlight:    make(map[uuid.UUID]*inFlight),
+	}
+}
+
+/*
+starts tracking a transaction taken by a worker
+the returned context must be used for processing it, done must be called once processing returns
+*/
+func (w *Watchdog) Track(ctx context.Context, curSession *session.Session, txn *types.Transaction) (context.Context, func()) {
+	txnCtx, cancel := context.WithCancelCause(ctx)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	/* workers are bounded by the scheduler semaphore, an empty free list is never expected */
+	worker := len(w.inFlight) + 1
+	if n := len(w.freeWorkers); n > 0 {
+		worker = w.freeWorkers[n-1]
+		w.freeWorkers = w.freeWorkers[:n-1]
+	}
+
+	entry := &inFlight{
+		worker:    worker,
+		txnID:     txn.ID,
+		sessionID: curSession.ID,
+		username:  curSession.Username,
+		operation: txn.Operation,
+		target:    txn.TargetPath,
+		startedAt: time.Now(),
+		cancel:    cancel,
+	}
+	w.inFlight[txn.ID] = entry
+
+	done := func() {
+		cancel(nil)
+
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		if current, ok := w.inFlight[entry.txnID]; ok && current == entry {
+			delete(w.inFlight, entry.txnID)
+			if entry.worker <= w.maxWorkers {
+				w.freeWorkers = append(w.freeWorkers, entry.worker)
+				/* keep the lowest free slot at the end */
+				sort.Sort(sort.Reverse(sort.IntSlice(w.freeWorkers)))
+			}
+		}
+	}
+
+	return txnCtx, done
+}
+
+/*
+checks in-flight transactions against the deadline every interval until ctx is done
+with cancel set, overrunning transactions are cancelled instead of only being flagged
+*/
+func (w *Watchdog) Run(ctx context.Context, interval, deadline time.Duration, cancel bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	zap.L().Info("Scheduler watchdog started",
+		zap.Duration("deadline", deadline),
+		zap.Bool("cancel", cancel),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("Scheduler watchdog shutting down")
+			return
+		case <-ticker.C:
+			w.check(deadline, cancel)
+		}
+	}
+}
+
+/* flags or cancels transactions running past the deadline, each one is handled once */
+func (w *Watchdog) check(deadline time.Duration, cancel bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	for _, entry := range w.inFlight {
+		running := now.Sub(entry.startedAt)
+		if entry.flagged || running < deadline {
+			continue
+		}
+		entry.flagged = true
+
+		zap.L().Warn("Transaction exceeded watchdog deadline",
+			zap.String("txnID", entry.txnID.String()),
+			zap.String("user", entry.username),
+			zap.Int("worker", entry.worker),
+			zap.String("operation", string(entry.operation)),
+			zap.String("targetPath", entry.target),
+			zap.Duration("running", running),
+			zap.Bool("cancelled", cancel),
+		)
+
+		if cancel {
+			entry.cancel(ErrWatchdogDeadline)
+		}
+	}
+}
+
+/* reports worker usage and the in-flight transactions, ordered by worker slot */
+func (w *Watchdog) Stats() (WorkerStats, []InFlightStats) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	transactions := make([]InFlightStats, 0, len(w.inFlight))
+	for _, entry := range w.inFlight {
+		transactions = append(transactions, InFlightStats{
+			Worker:     entry.worker,
+			ID:         entry.txnID.String(),
+			SessionID:  entry.sessionID.String(),
+			Username:   entry.username,
+			Operation:  entry.operation,
+			TargetPath: entry.target,
+			StartedAt:  entry.startedAt,
+			RunningMs:  now.Sub(entry.startedAt).Milliseconds(),
+			Flagged:    entry.flagged,
+		})
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Worker < transactions[j].Worker
+	})
+
+	busy := len(w.inFlight)
+	return WorkerStats{
+		Max:  w.maxWorkers,
+		Busy: busy,
+		Free: max(w.maxWorkers-busy, 0),
+	}, transactions
+}
@@ -0,0 +1,67 @@
+package wfq
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
+)
+
+/*
+	weighted fair queuing: every user is a flow with a weight from the fair_queuing config
+	each dispatched transaction advances the finish tag of its user by 1/weight
+	a free worker always goes to the user whose next transaction would finish first,
+	so backlogged users share workers in proportion to their weights
+*/
+
+/* resolves the LDAP groups of a user, used for group weights */
+type GroupResolver interface {
+	UserGroups(username string) ([]string, error)
+}
+
+/* WFQ Scheduler attached with curSession.Manager */
+type WFQScheduler struct {
+	curSessionManager *session.Manager
+	maxWorkers        int
+
+	/* for limiting spawning of goroutines */
+	semaphore chan struct{}
+	processor transprocessor.TransactionProcessor
+	groups    GroupResolver
+
+	/* tracks transactions handed to workers */
+	watchdog *scheduler.Watchdog
+
+	/* virtual time - start tag of the last dispatched transaction */
+	virtualTime float64
+
+	/* finish tag of the last transaction dispatched per user */
+	finish map[string]float64
+
+	/* weights of users with an active session, resolved once per session */
+	weights map[string]int
+
+	/* group weights are looked up in the background, the maps below are guarded by lookupMutex */
+	lookupMutex sync.Mutex
+
+	/* weights from finished lookups, taken over into weights by the dispatch loop */
+	resolved map[string]int
+
+	/* users with a lookup running */
+	lookups map[string]bool
+
+	/* earliest time the lookup of a user is attempted again after it failed */
+	retryAt map[string]time.Time
+}
+
+/* delay before a failed group lookup is attempted again, the default weight applies meanwhile */
+const lookupRetryInterval = 30 * time.Second
+
+/* a session and the finish tag its next transaction would get */
+type candidate struct {
+	session *session.Session
+	start   float64
+	finish  float64
+}
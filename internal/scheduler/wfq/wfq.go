@@ -0,0 +1,218 @@
+package wfq
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/scheduler"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* spawns a new WFQ scheduler */
+func NewWFQScheduler(sm *session.Manager, processor transprocessor.TransactionProcessor, groups GroupResolver, watchdog *scheduler.Watchdog) *WFQScheduler {
+	maxWorkers := scheduler.MaxWorkers()
+
+	return &WFQScheduler{
+		curSessionManager: sm,
+		maxWorkers:        maxWorkers,
+		semaphore:         make(chan struct{}, maxWorkers),
+		processor:         processor,
+		groups:            groups,
+		watchdog:          watchdog,
+		finish:            make(map[string]float64),
+		weights:           make(map[string]int),
+		resolved:          make(map[string]int),
+		lookups:           make(map[string]bool),
+		retryAt:           make(map[string]time.Time),
+	}
+}
+
+/*
+run the wfq scheduler with context
+a worker slot is taken first, so the user is picked with the latest state of the queues
+when no session has a ready transaction, the slot is returned and the scheduler sleeps until signalled
+*/
+func (w *WFQScheduler) Run(ctx context.Context) error {
+	for {
+		/* block if all workers are busy */
+		select {
+		case <-ctx.Done():
+			return nil
+		case w.semaphore <- struct{}{}:
+		}
+
+		if w.dispatchNext(ctx) {
+			continue
+		}
+
+		/* no work; a signal sent meanwhile is still buffered, so nothing is missed */
+		<-w.semaphore
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.curSessionManager.Wakeup():
+		}
+	}
+}
+
+/* dispatches a ready transaction of the user with the smallest finish tag, reports if anything was dispatched */
+func (w *WFQScheduler) dispatchNext(ctx context.Context) bool {
+	/* higher lanes are drained first, fair queuing applies within the lane */
+	lane, ok := w.curSessionManager.ReadyLane()
+	if !ok {
+		return false
+	}
+
+	sessions := w.curSessionManager.Sessions()
+	w.forgetInactive(sessions)
+
+	candidates := make([]candidate, 0, len(sessions))
+	for _, curSession := range sessions {
+		start := max(w.virtualTime, w.finish[curSession.Username])
+		candidates = append(candidates, candidate{
+			session: curSession,
+			start:   start,
+			finish:  start + 1/float64(w.weight(curSession.Username)),
+		})
+	}
+
+	/* stable, so equal tags keep the round robin order of sessions */
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].finish < candidates[j].finish
+	})
+
+	for _, c := range candidates {
+		/* sessions with nothing ready (empty, or waiting on other transactions for the same path) are passed over */
+		transaction := w.curSessionManager.PopReadyTransaction(c.session, lane)
+		if transaction == nil {
+			continue
+		}
+
+		w.virtualTime = c.start
+		w.finish[c.session.Username] = c.finish
+
+		/* go routine is available to be spawned */
+		go func(curSession *session.Session, transaction *types.Transaction) {
+			/* defer clearing the semaphore channel */
+			defer func() { <-w.semaphore }()
+
+			scheduler.ExecuteTransaction(ctx, w.curSessionManager, w.processor, w.watchdog, curSession, transaction)
+		}(c.session, transaction)
+
+		return true
+	}
+
+	return false
+}
+
+/*
+returns the weight of a user
+a weight configured for the user wins, otherwise the highest weight among its LDAP groups or the default
+groups are looked up in the background, the default weight is used (and not kept) until the lookup succeeded
+*/
+func (w *WFQScheduler) weight(username string) int {
+	if weight, ok := w.weights[username]; ok {
+		return weight
+	}
+
+	cfg := config.BackendConfig.FairQueuing
+	if weight, ok := cfg.Users[username]; ok {
+		w.weights[username] = weight
+		return weight
+	}
+	if len(cfg.Groups) == 0 {
+		w.weights[username] = cfg.DefaultWeight
+		return cfg.DefaultWeight
+	}
+
+	w.lookupMutex.Lock()
+	defer w.lookupMutex.Unlock()
+
+	if weight, ok := w.resolved[username]; ok {
+		delete(w.resolved, username)
+		w.weights[username] = weight
+		return weight
+	}
+
+	if !w.lookups[username] && !time.Now().Before(w.retryAt[username]) {
+		w.lookups[username] = true
+		go w.lookupWeight(username)
+	}
+
+	return cfg.DefaultWeight
+}
+
+/* resolves the group weight of a user via LDAP, outside the dispatch loop */
+func (w *WFQScheduler) lookupWeight(username string) {
+	groups, err := w.groups.UserGroups(username)
+
+	w.lookupMutex.Lock()
+	defer w.lookupMutex.Unlock()
+
+	delete(w.lookups, username)
+
+	if err != nil {
+		zap.L().Warn("Failed to resolve groups for scheduling weight, using default weight until retried",
+			zap.String("username", username),
+			zap.Duration("retryIn", lookupRetryInterval),
+			zap.Error(err),
+		)
+		w.retryAt[username] = time.Now().Add(lookupRetryInterval)
+		return
+	}
+	delete(w.retryAt, username)
+
+	cfg := config.BackendConfig.FairQueuing
+	weight := cfg.DefaultWeight
+
+	groupWeight := 0
+	for _, group := range groups {
+		groupWeight = max(groupWeight, cfg.Groups[group])
+	}
+	if groupWeight > 0 {
+		weight = groupWeight
+	}
+
+	w.resolved[username] = weight
+}
+
+/* drops state of users without an active session, a returning user starts at the current virtual time */
+func (w *WFQScheduler) forgetInactive(sessions []*session.Session) {
+	active := make(map[string]struct{}, len(sessions))
+	for _, curSession := range sessions {
+		active[curSession.Username] = struct{}{}
+	}
+
+	/* users still waiting for their group weight have a finish tag only */
+	for username := range w.finish {
+		if _, ok := active[username]; !ok {
+			delete(w.weights, username)
+			delete(w.finish, username)
+		}
+	}
+	for username := range w.weights {
+		if _, ok := active[username]; !ok {
+			delete(w.weights, username)
+		}
+	}
+
+	w.lookupMutex.Lock()
+	defer w.lookupMutex.Unlock()
+
+	for username := range w.resolved {
+		if _, ok := active[username]; !ok {
+			delete(w.resolved, username)
+		}
+	}
+	for username := range w.retryAt {
+		if _, ok := active[username]; !ok {
+			delete(w.retryAt, username)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/transprocessor"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* number of transactions processed at the same time, shared by all schedulers */
+func MaxWorkers() int {
+	/* calculate max workers */
+	maxProcs := runtime.GOMAXPROCS(0)
+	maxWorkers := config.BackendConfig.AppInfo.MaxWorkers
+
+	/*
+		incase of maxWorkers set less than or equal to 0,
+		use 75% of GOMAXPROCS to prevent starvation to other processes
+	*/
+	if maxWorkers <= 0 {
+		maxWorkers = int(float64(maxProcs) * 0.75)
+	}
+
+	/* Prevent over-allocation */
+	if maxWorkers > maxProcs {
+		maxWorkers = maxProcs
+	}
+
+	return maxWorkers
+}
+
+/*
+processes a transaction taken off a session queue, run by a worker goroutine of a scheduler
+the watchdog tracks the transaction while it is processed and may cancel it
+role of scheduler in handling transactions ends here
+*/
+func ExecuteTransaction(ctx context.Context, sm *session.Manager, processor transprocessor.TransactionProcessor, watchdog *Watchdog, curSession *session.Session, transaction *types.Transaction) {
+	/* a crash from here on executes the transaction again on startup */
+	sm.ClaimTransaction(transaction)
+
+	/*
+		process the transaction
+		* processTransaction handles transaction processing completely
+		* now it is responsible now responsible to execute it
+	*/
+	txnCtx, done := watchdog.Track(ctx, curSession, transaction)
+	err := processor.Process(txnCtx, curSession, transaction)
+	cause := context.Cause(txnCtx)
+	done()
+
+	if err != nil {
+		/* a transaction stopped by the watchdog is reported as failed, it is never retried */
+		if errors.Is(cause, ErrWatchdogDeadline) {
+			transaction.ExecStatus = false
+			if !strings.Contains(transaction.ErrorMsg, ErrWatchdogDeadline.Error()) {
+				transaction.ErrorMsg = fmt.Sprintf("%s: %s", ErrWatchdogDeadline, transaction.ErrorMsg)
+			}
+			/* %v drops a retryable error from the chain */
+			err = fmt.Errorf("%w: %v", ErrWatchdogDeadline, err)
+		} else if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			/* interrupted by shutdown; left in the durable queue, it is executed again on startup */
+			zap.L().Warn("Transaction interrupted by shutdown",
+				zap.String("txnID", transaction.ID.String()),
+			)
+			return
+		}
+
+		/* transient failures go back to the session queue instead of being reported */
+		var retryErr *transprocessor.RetryableError
+		if errors.As(err, &retryErr) {
+			zap.L().Warn("Requeueing transaction after transient failure",
+				zap.String("txnID", transaction.ID.String()),
+				zap.Int("attempts", transaction.Attempts),
+				zap.Duration("backoff", retryErr.Backoff),
+				zap.Error(retryErr.Err),
+			)
+			if err := sm.RequeueTransaction(curSession, transaction, retryErr.Backoff); err != nil {
+				zap.L().Error("Failed to requeue transaction",
+					zap.Error(err),
+				)
+			}
+			return
+		}
+
+		zap.L().Error("Failed to process transaction",
+			zap.Error(err),
+		)
+	}
+
+	/* we assume the transaction has been processed -> updated Redis */
+	transaction.Status = types.StatusSuccess
+
+	/* update duration of transaction execution */
+	elapsed := time.Since(transaction.Timestamp)
+	transaction.DurationMs = elapsed.Milliseconds()
+
+	/* update session state after transaction execution */
+	sm.CompleteTransaction(curSession, transaction)
+}
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+)
+
+/* resolves ACL entities (users and groups) against the LDAP server */
+type LDAPDirectory struct{}
+
+/* checks if a user exists in LDAP, by username or uid number */
+func (LDAPDirectory) UserExists(name string) (bool, error) {
+	filter := fmt.Sprintf("(&(objectClass=inetOrgPerson)(uid=%s))", ldap.EscapeFilter(name))
+	if _, err := strconv.ParseUint(name, 10, 32); err == nil {
+		filter = fmt.Sprintf("(&(objectClass=posixAccount)(uidNumber=%s))", name)
+	}
+
+	return entryExists(filter)
+}
+
+/* checks if a group exists in LDAP, by group name or gid number */
+func (LDAPDirectory) GroupExists(name string) (bool, error) {
+	filter := fmt.Sprintf("(&(|(objectClass=posixGroup)(objectClass=groupOfNames))(cn=%s))", ldap.EscapeFilter(name))
+	if _, err := strconv.ParseUint(name, 10, 32); err == nil {
+		filter = fmt.Sprintf("(&(objectClass=posixGroup)(gidNumber=%s))", name)
+	}
+
+	return entryExists(filter)
+}
+
+/* reports if any entry under the search base matches the filter */
+func entryExists(filter string) (bool, error) {
+	l, err := dialLDAP()
+	if err != nil {
+		return false, err
+	}
+	defer l.Close()
+
+	searchRequest := ldap.NewSearchRequest(
+		config.BackendConfig.Authentication.LDAPConfig.SearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		/* one match is enough */
+		1, 0, false,
+		filter,
+		/* We only need the DN */
+		[]string{"dn"},
+		nil,
+	)
+
+	sr, err := l.Search(searchRequest)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return false, err
+	}
+
+	return sr != nil && len(sr.Entries) > 0, nil
+}
+
+/* connects to the LDAP server and binds as admin */
+func dialLDAP() (*ldap.Conn, error) {
+	var l *ldap.Conn
+	var err error
+	ldapAddress := config.BackendConfig.Authentication.LDAPConfig.Address
+
+	/* check if TLS is enabled */
+	if config.BackendConfig.Authentication.LDAPConfig.TLS {
+		l, err = ldap.DialURL(ldapAddress, ldap.DialWithTLSConfig(&tls.Config{
+
+			/* true if using self-signed certs (not recommended) */
+			InsecureSkipVerify: true,
+		}))
+	} else {
+		l, err = ldap.DialURL(ldapAddress)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	/* authenticating with the ldap server with admin */
+	if err := l.Bind(config.BackendConfig.Authentication.LDAPConfig.AdminDN,
+		config.BackendConfig.Authentication.LDAPConfig.AdminPassword,
+	); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+/* returns the names of the LDAP groups a user is a member of */
+func (LDAPDirectory) UserGroups(username string) ([]string, error) {
+	l, err := dialLDAP()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	/* groupOfNames lists members by DN, so the DN of the user is needed */
+	userRequest := ldap.NewSearchRequest(
+		config.BackendConfig.Authentication.LDAPConfig.SearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		1, 0, false,
+		fmt.Sprintf("(uid=%s)", ldap.EscapeFilter(username)),
+		[]string{"dn"},
+		nil,
+	)
+
+	userResult, err := l.Search(userRequest)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("(&(objectClass=posixGroup)(memberUid=%s))", ldap.EscapeFilter(username))
+	if userResult != nil && len(userResult.Entries) > 0 {
+		filter = fmt.Sprintf("(|%s(&(objectClass=groupOfNames)(member=%s)))",
+			filter, ldap.EscapeFilter(userResult.Entries[0].DN),
+		)
+	}
+
+	groupRequest := ldap.NewSearchRequest(
+		config.BackendConfig.Authentication.LDAPConfig.SearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		filter,
+		/* We only need the group names */
+		[]string{"cn"},
+		nil,
+	)
+
+	groupResult, err := l.Search(groupRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0, len(groupResult.Entries))
+	for _, entry := range groupResult.Entries {
+		if name := entry.GetAttributeValue("cn"); name != "" {
+			groups = append(groups, name)
+		}
+	}
+
+	return groups, nil
+}
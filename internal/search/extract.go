@@ -1,7 +1,6 @@
 package search
 
 import (
-	"crypto/tls"
 	"fmt"
 
 	"github.com/PythonHacker24/linux-acl-management-backend/config"
@@ -17,34 +16,12 @@ import (
 /* returns search for query in the pool of all users in LDAP server */
 func GetAllUsersFromLDAP(query string) ([]User, error) {
 
-	var l *ldap.Conn
-	var err error
-	ldapAddress := config.BackendConfig.Authentication.LDAPConfig.Address
-
-	/* check if TLS is enabled */
-	if config.BackendConfig.Authentication.LDAPConfig.TLS {
-		l, err = ldap.DialURL(ldapAddress, ldap.DialWithTLSConfig(&tls.Config{
-
-			/* true if using self-signed certs (not recommended) */
-			InsecureSkipVerify: true,
-		}))
-	} else {
-		l, err = ldap.DialURL(ldapAddress)
-	}
-
+	l, err := dialLDAP()
 	if err != nil {
 		return nil, err
 	}
 	defer l.Close()
 
-	/* authenticating with the ldap server with admin */
-	err = l.Bind(config.BackendConfig.Authentication.LDAPConfig.AdminDN,
-		config.BackendConfig.Authentication.LDAPConfig.AdminPassword,
-	)
-	if err != nil {
-		return nil, err
-	}
-
 	/* wild card to avoid errors */
 	if query == "" {
 		query = "*"
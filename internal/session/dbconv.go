@@ -84,19 +84,65 @@ func ConvertTransactionPendingtoStoreParams(tx types.Transaction) (postgresql.Cr
 		durationMs = pgtype.Int8{Int64: tx.DurationMs, Valid: true}
 	}
 
+	/* handle optional source path (copyacl) */
+	var sourcePath pgtype.Text
+	if tx.SourcePath != "" {
+		sourcePath = pgtype.Text{String: tx.SourcePath, Valid: true}
+	}
+
+	/* handle optional error of the latest attempt */
+	var lastError pgtype.Text
+	if tx.LastError != "" {
+		lastError = pgtype.Text{String: tx.LastError, Valid: true}
+	}
+
+	/* marshal changeset steps (empty list for other operations) */
+	stepsJSON, err := marshalSteps(tx.Steps)
+	if err != nil {
+		return postgresql.CreatePendingTransactionPQParams{}, err
+	}
+
+	/* marshal ACL image to restore (restoreacl/copyacl), empty list for other operations */
+	acl := tx.ACL
+	if acl == nil {
+		acl = []types.ACLRule{}
+	}
+	aclJSON, err := json.Marshal(acl)
+	if err != nil {
+		return postgresql.CreatePendingTransactionPQParams{}, fmt.Errorf("failed to marshal ACL: %w", err)
+	}
+
+	/* marshal ACL the target is expected to hold before a revert */
+	expectedACL := tx.ExpectedACL
+	if expectedACL == nil {
+		expectedACL = []types.ACLRule{}
+	}
+	expectedACLJSON, err := json.Marshal(expectedACL)
+	if err != nil {
+		return postgresql.CreatePendingTransactionPQParams{}, fmt.Errorf("failed to marshal expected ACL: %w", err)
+	}
+
 	return postgresql.CreatePendingTransactionPQParams{
-		ID:         tx.ID,
-		SessionID:  tx.SessionID,
-		Timestamp:  timestamp,
-		Operation:  string(tx.Operation),
-		TargetPath: tx.TargetPath,
-		Entries:    entriesJSON,
-		Status:     string(tx.Status),
-		Execstatus: tx.ExecStatus,
-		ErrorMsg:   errorMsg,
-		Output:     output,
-		ExecutedBy: tx.ExecutedBy,
-		DurationMs: durationMs,
+		ID:          tx.ID,
+		SessionID:   tx.SessionID,
+		Timestamp:   timestamp,
+		Operation:   string(tx.Operation),
+		TargetPath:  tx.TargetPath,
+		Entries:     entriesJSON,
+		Status:      string(tx.Status),
+		Execstatus:  tx.ExecStatus,
+		ErrorMsg:    errorMsg,
+		Output:      output,
+		ExecutedBy:  tx.ExecutedBy,
+		DurationMs:  durationMs,
+		SourcePath:  sourcePath,
+		Attempts:    int32(tx.Attempts),
+		LastError:   lastError,
+		Steps:       stepsJSON,
+		Recursive:   tx.Recursive,
+		Acl:         aclJSON,
+		ExpectedAcl: expectedACLJSON,
+		Priority:    string(normalizePriority(tx.Priority)),
 	}, nil
 }
 
@@ -132,6 +178,44 @@ func ConvertTransactionResulttoStoreParams(tx types.Transaction) (postgresql.Cre
 		durationMs = pgtype.Int8{Int64: tx.DurationMs, Valid: true}
 	}
 
+	/* marshal ACL of the target after the transaction (empty list if nothing was read) */
+	acl := tx.ACL
+	if acl == nil {
+		acl = []types.ACLRule{}
+	}
+	aclJSON, err := json.Marshal(acl)
+	if err != nil {
+		return postgresql.CreateResultsTransactionPQParams{}, fmt.Errorf("failed to marshal ACL: %w", err)
+	}
+
+	/* marshal ACL captured before the change (empty list if nothing was captured) */
+	beforeACL := tx.BeforeACL
+	if beforeACL == nil {
+		beforeACL = []types.ACLRule{}
+	}
+	beforeACLJSON, err := json.Marshal(beforeACL)
+	if err != nil {
+		return postgresql.CreateResultsTransactionPQParams{}, fmt.Errorf("failed to marshal before ACL: %w", err)
+	}
+
+	/* handle optional source path (copyacl) */
+	var sourcePath pgtype.Text
+	if tx.SourcePath != "" {
+		sourcePath = pgtype.Text{String: tx.SourcePath, Valid: true}
+	}
+
+	/* handle optional error of the latest attempt */
+	var lastError pgtype.Text
+	if tx.LastError != "" {
+		lastError = pgtype.Text{String: tx.LastError, Valid: true}
+	}
+
+	/* marshal changeset steps (empty list for other operations) */
+	stepsJSON, err := marshalSteps(tx.Steps)
+	if err != nil {
+		return postgresql.CreateResultsTransactionPQParams{}, err
+	}
+
 	return postgresql.CreateResultsTransactionPQParams{
 		ID:         tx.ID,
 		SessionID:  tx.SessionID,
@@ -145,5 +229,158 @@ func ConvertTransactionResulttoStoreParams(tx types.Transaction) (postgresql.Cre
 		Output:     output,
 		ExecutedBy: tx.ExecutedBy,
 		DurationMs: durationMs,
+		Acl:        aclJSON,
+		BeforeAcl:  beforeACLJSON,
+		SourcePath: sourcePath,
+		Attempts:   int32(tx.Attempts),
+		LastError:  lastError,
+		Steps:      stepsJSON,
+		Priority:   string(normalizePriority(tx.Priority)),
 	}, nil
 }
+
+/* converts an archived transaction result back into project transaction struct */
+func ConvertResultsArchiveToTransaction(row postgresql.ResultsTransactionsArchive) (types.Transaction, error) {
+	tx := types.Transaction{
+		ID:         row.ID,
+		SessionID:  row.SessionID,
+		Timestamp:  row.Timestamp.Time,
+		Operation:  types.OperationType(row.Operation),
+		TargetPath: row.TargetPath,
+		SourcePath: row.SourcePath.String,
+		Status:     types.TxnStatus(row.Status),
+		ExecStatus: row.Execstatus,
+		ErrorMsg:   row.ErrorMsg.String,
+		Output:     row.Output.String,
+		ExecutedBy: row.ExecutedBy,
+		DurationMs: row.DurationMs.Int64,
+		Attempts:   int(row.Attempts),
+		LastError:  row.LastError.String,
+		Priority:   types.Priority(row.Priority),
+	}
+
+	if err := json.Unmarshal(row.Entries, &tx.Entries); err != nil {
+		return types.Transaction{}, fmt.Errorf("failed to unmarshal ACL entries: %w", err)
+	}
+
+	if len(row.Acl) != 0 {
+		if err := json.Unmarshal(row.Acl, &tx.ACL); err != nil {
+			return types.Transaction{}, fmt.Errorf("failed to unmarshal ACL: %w", err)
+		}
+	}
+
+	if len(row.BeforeAcl) != 0 {
+		if err := json.Unmarshal(row.BeforeAcl, &tx.BeforeACL); err != nil {
+			return types.Transaction{}, fmt.Errorf("failed to unmarshal before ACL: %w", err)
+		}
+	}
+
+	if err := unmarshalSteps(row.Steps, &tx); err != nil {
+		return types.Transaction{}, err
+	}
+
+	return tx, nil
+}
+
+/* converts an archived pending transaction back into project transaction struct */
+func ConvertPendingArchiveToTransaction(row postgresql.PendingTransactionsArchive) (types.Transaction, error) {
+	tx := types.Transaction{
+		ID:         row.ID,
+		SessionID:  row.SessionID,
+		Timestamp:  row.Timestamp.Time,
+		Operation:  types.OperationType(row.Operation),
+		TargetPath: row.TargetPath,
+		SourcePath: row.SourcePath.String,
+		Status:     types.TxnStatus(row.Status),
+		ExecStatus: row.Execstatus,
+		ErrorMsg:   row.ErrorMsg.String,
+		Output:     row.Output.String,
+		ExecutedBy: row.ExecutedBy,
+		DurationMs: row.DurationMs.Int64,
+		Attempts:   int(row.Attempts),
+		LastError:  row.LastError.String,
+		Recursive:  row.Recursive,
+		Priority:   types.Priority(row.Priority),
+	}
+
+	if err := json.Unmarshal(row.Entries, &tx.Entries); err != nil {
+		return types.Transaction{}, fmt.Errorf("failed to unmarshal ACL entries: %w", err)
+	}
+
+	if err := unmarshalSteps(row.Steps, &tx); err != nil {
+		return types.Transaction{}, err
+	}
+
+	if len(row.Acl) != 0 {
+		if err := json.Unmarshal(row.Acl, &tx.ACL); err != nil {
+			return types.Transaction{}, fmt.Errorf("failed to unmarshal ACL: %w", err)
+		}
+		if len(tx.ACL) == 0 {
+			tx.ACL = nil
+		}
+	}
+
+	if len(row.ExpectedAcl) != 0 {
+		if err := json.Unmarshal(row.ExpectedAcl, &tx.ExpectedACL); err != nil {
+			return types.Transaction{}, fmt.Errorf("failed to unmarshal expected ACL: %w", err)
+		}
+		if len(tx.ExpectedACL) == 0 {
+			tx.ExpectedACL = nil
+		}
+	}
+
+	return tx, nil
+}
+
+/* marshals changeset steps for the archive */
+func marshalSteps(steps []types.ChangesetStep) ([]byte, error) {
+	if steps == nil {
+		steps = []types.ChangesetStep{}
+	}
+	stepsJSON, err := json.Marshal(steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal changeset steps: %w", err)
+	}
+	return stepsJSON, nil
+}
+
+/* unmarshals archived changeset steps into the transaction (nil if it has none) */
+func unmarshalSteps(stepsJSON []byte, tx *types.Transaction) error {
+	if len(stepsJSON) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stepsJSON, &tx.Steps); err != nil {
+		return fmt.Errorf("failed to unmarshal changeset steps: %w", err)
+	}
+	if len(tx.Steps) == 0 {
+		tx.Steps = nil
+	}
+	return nil
+}
+
+/* converts a transaction into the unified view returned by transaction lookups */
+func ConvertTransactionToView(tx types.Transaction, source string) TransactionView {
+	return TransactionView{
+		ID:         tx.ID.String(),
+		Source:     source,
+		Operation:  string(tx.Operation),
+		TargetPath: tx.TargetPath,
+		SourcePath: tx.SourcePath,
+		Recursive:  tx.Recursive,
+		Priority:   string(normalizePriority(tx.Priority)),
+		Status:     string(tx.Status),
+		ExecStatus: tx.ExecStatus,
+		Output:     tx.Output,
+		ErrorMsg:   tx.ErrorMsg,
+		Warnings:   tx.Warnings,
+		Failures:   tx.Failures,
+		Entries:    tx.Entries,
+		Steps:      tx.Steps,
+		ExecutedBy: tx.ExecutedBy,
+		Timestamp:  tx.Timestamp,
+		DurationMs: tx.DurationMs,
+		Attempts:   tx.Attempts,
+		LastError:  tx.LastError,
+		Replayed:   tx.Replayed,
+	}
+}
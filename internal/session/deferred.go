@@ -0,0 +1,184 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* user agent of sessions created by the backend to run transactions of users who aren't logged in */
+const backgroundUserAgent = "laclm-background"
+
+/* max deferred transactions claimed from PostgreSQL at once */
+const deferredClaimBatch = 100
+
+/*
+holds a transaction back in the deferred queue in PostgreSQL until txn.ExecuteAt
+deferred transactions don't belong to a session queue, they survive the session ending
+*/
+func (m *Manager) DeferTransaction(username string, txn *types.Transaction) error {
+	txnJSON, err := json.Marshal(txn)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deferred transaction: %w", err)
+	}
+
+	var executeAt pgtype.Timestamptz
+	if err := executeAt.Scan(*txn.ExecuteAt); err != nil {
+		return fmt.Errorf("failed to convert execute at time: %w", err)
+	}
+
+	if _, err := m.archivalPQ.CreateDeferredTransactionPQ(context.Background(), postgresql.CreateDeferredTransactionPQParams{
+		ID:          txn.ID,
+		Username:    username,
+		ExecuteAt:   executeAt,
+		Transaction: txnJSON,
+	}); err != nil {
+		return fmt.Errorf("failed to store deferred transaction: %w", err)
+	}
+
+	return nil
+}
+
+/* lists deferred transactions of a user that are still waiting to be dispatched */
+func (m *Manager) ListDeferredTransactions(username string) ([]types.Transaction, error) {
+	rows, err := m.archivalPQ.GetScheduledDeferredTransactionsByUserPQ(context.Background(), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deferred transactions: %w", err)
+	}
+
+	transactions := make([]types.Transaction, 0, len(rows))
+	for _, row := range rows {
+		var tx types.Transaction
+		if err := json.Unmarshal(row.Transaction, &tx); err != nil {
+			m.errCh <- fmt.Errorf("failed to unmarshal deferred transaction %s: %w", row.ID, err)
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+/* cancels a deferred transaction that wasn't dispatched yet and archives it as cancelled */
+func (m *Manager) cancelDeferredTransaction(username string, txnID uuid.UUID) (*types.Transaction, error) {
+	row, err := m.archivalPQ.CancelDeferredTransactionPQ(context.Background(), postgresql.CancelDeferredTransactionPQParams{
+		ID:       txnID,
+		Username: username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to cancel deferred transaction: %w", err)
+	}
+
+	var tx types.Transaction
+	if err := json.Unmarshal(row.Transaction, &tx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deferred transaction: %w", err)
+	}
+
+	tx.Status = types.StatusCancelled
+	tx.Output = "Transaction cancelled by user"
+
+	params, err := ConvertTransactionResulttoStoreParams(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert cancelled transaction to archive format: %w", err)
+	}
+	if _, err := m.archivalPQ.CreateResultsTransactionPQ(context.Background(), params); err != nil {
+		return nil, fmt.Errorf("failed to archive cancelled transaction: %w", err)
+	}
+
+	return &tx, nil
+}
+
+/*
+schedules a transaction on behalf of a user who may not be logged in
+a session is created for the user if there is none, it expires like any other session
+*/
+func (m *Manager) ScheduleBackgroundTransaction(username string, txn *types.Transaction) error {
+	err := m.ScheduleTransaction(username, txn)
+	if !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+
+	if _, err := m.CreateSession(username, "", backgroundUserAgent); err != nil {
+		return fmt.Errorf("failed to create background session: %w", err)
+	}
+
+	return m.ScheduleTransaction(username, txn)
+}
+
+/*
+dispatches due deferred transactions to the scheduler every interval until ctx is done
+transactions are claimed with SKIP LOCKED, so several backends can share the deferred queue
+*/
+func (m *Manager) RunDeferredDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.dispatchDueTransactions(ctx)
+		}
+	}
+}
+
+/*
+moves every due deferred transaction into the session queue of its user
+claiming a transaction also writes it to the durable queue (same statement), so a crash can't lose it
+the round stops at the first transaction that can't be queued, the rest of the batch waits for the next one
+*/
+func (m *Manager) dispatchDueTransactions(ctx context.Context) {
+	for {
+		rows, err := m.archivalPQ.ClaimDueDeferredTransactionsPQ(ctx, postgresql.ClaimDueDeferredTransactionsPQParams{
+			Limit: deferredClaimBatch,
+			Owner: m.instanceID,
+		})
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to claim due deferred transactions: %w", err)
+			return
+		}
+
+		for i, row := range rows {
+			var tx types.Transaction
+			if err := json.Unmarshal(row.Transaction, &tx); err != nil {
+				m.errCh <- fmt.Errorf("failed to unmarshal deferred transaction %s: %w", row.ID, err)
+
+				/* it would fail the same way when reclaimed on startup */
+				m.ackQueuedTransaction(&types.Transaction{ID: row.ID})
+				continue
+			}
+
+			/* duration is measured from the moment the transaction is queued */
+			tx.Timestamp = time.Now()
+
+			if err := m.ScheduleBackgroundTransaction(row.Username, &tx); err != nil {
+				m.errCh <- fmt.Errorf("failed to dispatch deferred transaction %s: %w", row.ID, err)
+
+				/* put it and the rest of the batch back (out of the durable queue too), the next round picks them up */
+				for _, rest := range rows[i:] {
+					if err := m.archivalPQ.ResetDeferredTransactionPQ(ctx, rest.ID); err != nil {
+						m.errCh <- fmt.Errorf("failed to reset deferred transaction %s: %w", rest.ID, err)
+					}
+				}
+				return
+			}
+		}
+
+		if len(rows) < deferredClaimBatch {
+			return
+		}
+	}
+}
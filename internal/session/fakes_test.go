@@ -0,0 +1,313 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
+)
+
+/*
+	in-memory stand-ins for Redis and PostgreSQL, enough for the manager to queue transactions
+	expirations are ignored, the tests don't run long enough for them to matter
+*/
+
+var errFakeUnsupported = errors.New("not supported by the fake")
+
+/* Redis client keeping strings, hashes and lists in maps */
+type fakeRedis struct {
+	mutex   sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	lists   map[string][]string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		strings: make(map[string]string),
+		hashes:  make(map[string]map[string]string),
+		lists:   make(map[string][]string),
+	}
+}
+
+/* values are stored the way go-redis sends them */
+func redisString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.strings[key] = redisString(value)
+	return nil
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	val, ok := r.strings[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return val, nil
+}
+
+func (r *fakeRedis) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.strings[key]; ok {
+		return false, nil
+	}
+	r.strings[key] = redisString(value)
+	return true, nil
+}
+
+func (r *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		_, isString := r.strings[key]
+		_, isHash := r.hashes[key]
+		_, isList := r.lists[key]
+		if isString || isHash || isList {
+			deleted++
+		}
+		delete(r.strings, key)
+		delete(r.hashes, key)
+		delete(r.lists, key)
+	}
+
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(deleted)
+	return cmd
+}
+
+func (r *fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	cmd := redis.NewScanCmd(ctx, nil)
+	cmd.SetErr(errFakeUnsupported)
+	return cmd
+}
+
+func (r *fakeRedis) HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	hash, ok := r.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		r.hashes[key] = hash
+	}
+
+	var added int64
+	for i := 0; i+1 < len(values); i += 2 {
+		field := redisString(values[i])
+		if _, ok := hash[field]; !ok {
+			added++
+		}
+		hash[field] = redisString(values[i+1])
+	}
+
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(added)
+	return cmd
+}
+
+func (r *fakeRedis) HGet(ctx context.Context, key, field string) *redis.StringCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cmd := redis.NewStringCmd(ctx)
+	val, ok := r.hashes[key][field]
+	if !ok {
+		cmd.SetErr(redis.Nil)
+		return cmd
+	}
+	cmd.SetVal(val)
+	return cmd
+}
+
+func (r *fakeRedis) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for _, field := range fields {
+		if _, ok := r.hashes[key][field]; ok {
+			delete(r.hashes[key], field)
+			deleted++
+		}
+	}
+
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(deleted)
+	return cmd
+}
+
+func (r *fakeRedis) RPush(ctx context.Context, key string, value interface{}) *redis.IntCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lists[key] = append(r.lists[key], redisString(value))
+
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(int64(len(r.lists[key])))
+	return cmd
+}
+
+func (r *fakeRedis) LRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	l := r.lists[key]
+	n := int64(len(l))
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+
+	cmd := redis.NewStringSliceCmd(ctx)
+	if start > stop {
+		cmd.SetVal([]string{})
+		return cmd
+	}
+	cmd.SetVal(append([]string(nil), l[start:stop+1]...))
+	return cmd
+}
+
+func (r *fakeRedis) PSubscribe(ctx context.Context, patterns ...string) (*redis.PubSub, error) {
+	return nil, errFakeUnsupported
+}
+
+func (r *fakeRedis) HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	hash := make(map[string]string, len(r.hashes[key]))
+	for field, val := range r.hashes[key] {
+		hash[field] = val
+	}
+
+	cmd := redis.NewMapStringStringCmd(ctx)
+	cmd.SetVal(hash)
+	return cmd
+}
+
+func (r *fakeRedis) FlushAll(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.strings = make(map[string]string)
+	r.hashes = make(map[string]map[string]string)
+	r.lists = make(map[string][]string)
+	return nil
+}
+
+func (r *fakeRedis) HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetErr(errFakeUnsupported)
+	return cmd
+}
+
+func (r *fakeRedis) Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(0)
+	return cmd
+}
+
+/* statement run through the fake database */
+type fakeExec struct {
+	sql  string
+	args []interface{}
+}
+
+/*
+PostgreSQL connection recording statements, queries returning rows aren't supported
+fail decides about the error of a statement, failed statements aren't recorded
+*/
+type fakeDB struct {
+	mutex sync.Mutex
+	execs []fakeExec
+	fail  func(sql string) error
+}
+
+func (db *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if db.fail != nil {
+		if err := db.fail(sql); err != nil {
+			return pgconn.NewCommandTag(""), err
+		}
+	}
+
+	db.execs = append(db.execs, fakeExec{sql: sql, args: args})
+	return pgconn.NewCommandTag(""), nil
+}
+
+/* sets the function deciding about statement errors */
+func (db *fakeDB) failWith(fail func(sql string) error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	db.fail = fail
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, errFakeUnsupported
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return fakeRow{}
+}
+
+/* statements run so far */
+func (db *fakeDB) statements() []fakeExec {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	return append([]fakeExec(nil), db.execs...)
+}
+
+type fakeRow struct{}
+
+func (fakeRow) Scan(dest ...any) error {
+	return errFakeUnsupported
+}
+
+/* circuit breaker of a backend without remote daemons */
+type noCircuits struct{}
+
+func (noCircuits) CircuitOpen(addr string) bool                     { return false }
+func (noCircuits) AcquireCircuit(addr string, txnID uuid.UUID) bool { return true }
+func (noCircuits) ReleaseCircuit(addr string, txnID uuid.UUID)      {}
+
+/* manager backed by the fakes, errors it reports are collected on the returned channel */
+func newFakeManager() (*Manager, *fakeRedis, *fakeDB, chan error) {
+	rdb := newFakeRedis()
+	db := &fakeDB{}
+	errCh := make(chan error, 100)
+
+	return NewManager(rdb, postgresql.New(db), nil, noCircuits{}, errCh), rdb, db, errCh
+}
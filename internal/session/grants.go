@@ -0,0 +1,349 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* max grants claimed from PostgreSQL at once by the reaper */
+const grantClaimBatch = 100
+
+/* errors returned while changing grants */
+var (
+	ErrGrantNotFound = errors.New("active grant not found")
+	ErrInvalidExpiry = errors.New("expiry must be in the future")
+)
+
+/* grant notices */
+const (
+	GrantNoticeExpiring = "grant_expiring"
+	GrantNoticeExpired  = "grant_expired"
+)
+
+/* converts a stored grant into its frontend-safe view */
+func convertGrantToView(grant postgresql.AclGrant) GrantView {
+	view := GrantView{
+		ID:          grant.ID.String(),
+		TxnID:       grant.TxnID.String(),
+		TargetPath:  grant.TargetPath,
+		EntityType:  grant.EntityType,
+		Entity:      grant.Entity,
+		Permissions: grant.Permissions,
+		IsDefault:   grant.IsDefault,
+		Recursive:   grant.Recursive,
+		ExpiresAt:   grant.ExpiresAt.Time,
+		Status:      grant.Status,
+	}
+	if grant.RevokeTxnID.Valid {
+		view.RevokeTxnID = uuid.UUID(grant.RevokeTxnID.Bytes).String()
+	}
+	return view
+}
+
+/*
+tracks time-bound grants of a processed setfacl transaction
+a later change of the same entry on the same path replaces an earlier grant
+*/
+func (m *Manager) recordGrants(txn *types.Transaction) {
+	if txn.Operation != types.OperationSetACL {
+		return
+	}
+
+	ctx := context.Background()
+	for _, entry := range txn.Entries {
+		if !entryWritten(txn, entry) || entry.Entity == "" || (entry.EntityType != "user" && entry.EntityType != "group") {
+			continue
+		}
+		if entry.Action != "add" && entry.Action != "modify" && entry.Action != "remove" {
+			continue
+		}
+
+		if err := m.archivalPQ.SupersedeGrantsPQ(ctx, postgresql.SupersedeGrantsPQParams{
+			TargetPath: txn.TargetPath,
+			EntityType: entry.EntityType,
+			Entity:     entry.Entity,
+			IsDefault:  entry.IsDefault,
+		}); err != nil {
+			m.errCh <- fmt.Errorf("failed to supersede grants of %s:%s on %s: %w", entry.EntityType, entry.Entity, txn.TargetPath, err)
+		}
+
+		if entry.ExpiresAt == nil {
+			continue
+		}
+
+		var expiresAt pgtype.Timestamptz
+		if err := expiresAt.Scan(*entry.ExpiresAt); err != nil {
+			m.errCh <- fmt.Errorf("failed to convert grant expiry: %w", err)
+			continue
+		}
+
+		if _, err := m.archivalPQ.CreateGrantPQ(ctx, postgresql.CreateGrantPQParams{
+			ID:          uuid.New(),
+			Username:    txn.ExecutedBy,
+			TxnID:       txn.ID,
+			TargetPath:  txn.TargetPath,
+			EntityType:  entry.EntityType,
+			Entity:      entry.Entity,
+			Permissions: entry.Permissions,
+			IsDefault:   entry.IsDefault,
+			Recursive:   txn.Recursive,
+			ExpiresAt:   expiresAt,
+		}); err != nil {
+			m.errCh <- fmt.Errorf("failed to store grant of %s:%s on %s: %w", entry.EntityType, entry.Entity, txn.TargetPath, err)
+		}
+	}
+}
+
+/*
+reports if an entry was written to at least one path
+a recursive walk may fail on some paths only; daemons don't count paths, so a walk with failed paths counts as written
+recording an entry that wasn't written is harmless, its removal is a no-op
+*/
+func entryWritten(txn *types.Transaction, entry types.ACLEntry) bool {
+	if entry.Success || entry.AppliedPaths > 0 {
+		return true
+	}
+	return txn.Recursive && len(txn.Failures) > 0
+}
+
+/*
+puts a grant back in place when the transaction removing it failed or was cancelled
+the reaper tries again once the grant expires, which is pushed out with each failed attempt (up to an hour)
+*/
+func (m *Manager) rearmGrant(txn *types.Transaction) {
+	if txn.Operation != types.OperationSetACL || txn.ExecStatus {
+		return
+	}
+
+	row, err := m.archivalPQ.RearmGrantPQ(context.Background(), pgtype.UUID{Bytes: txn.ID, Valid: true})
+	if err != nil {
+		/* not the removal of a grant */
+		if errors.Is(err, pgx.ErrNoRows) {
+			return
+		}
+		m.errCh <- fmt.Errorf("failed to rearm grant revoked by %s: %w", txn.ID, err)
+		return
+	}
+
+	zap.L().Warn("Grant removal failed, retrying later",
+		zap.String("grantID", row.ID.String()),
+		zap.String("txnID", txn.ID.String()),
+		zap.Int32("attempts", row.RevokeAttempts),
+		zap.Time("retryAt", row.ExpiresAt.Time),
+	)
+}
+
+/* lists active time-bound grants issued by a user */
+func (m *Manager) ListGrants(username string) ([]GrantView, error) {
+	rows, err := m.archivalPQ.GetActiveGrantsByUserPQ(context.Background(), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grants: %w", err)
+	}
+
+	grants := make([]GrantView, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, convertGrantToView(row))
+	}
+
+	return grants, nil
+}
+
+/* moves the expiry of an active grant, the expiry notice is sent again for the new time */
+func (m *Manager) ExtendGrant(username string, grantID uuid.UUID, expiresAt time.Time) (*GrantView, error) {
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	var expiry pgtype.Timestamptz
+	if err := expiry.Scan(expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to convert grant expiry: %w", err)
+	}
+
+	row, err := m.archivalPQ.ExtendGrantPQ(context.Background(), postgresql.ExtendGrantPQParams{
+		ID:        grantID,
+		Username:  username,
+		ExpiresAt: expiry,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to extend grant: %w", err)
+	}
+
+	view := convertGrantToView(row)
+	return &view, nil
+}
+
+/* revokes an active grant before it expires by scheduling its removal */
+func (m *Manager) RevokeGrant(username string, grantID uuid.UUID) (*GrantView, error) {
+	ctx := context.Background()
+
+	row, err := m.archivalPQ.RevokeGrantPQ(ctx, postgresql.RevokeGrantPQParams{
+		ID:       grantID,
+		Username: username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke grant: %w", err)
+	}
+
+	txnID, err := m.scheduleGrantRemoval(row)
+	if err != nil {
+		if err := m.archivalPQ.ReactivateGrantPQ(ctx, row.ID); err != nil {
+			m.errCh <- fmt.Errorf("failed to reactivate grant %s: %w", row.ID, err)
+		}
+		return nil, err
+	}
+
+	view := convertGrantToView(row)
+	view.RevokeTxnID = txnID.String()
+	return &view, nil
+}
+
+/* schedules the "remove" transaction undoing a grant on behalf of the user who issued it */
+func (m *Manager) scheduleGrantRemoval(grant postgresql.AclGrant) (uuid.UUID, error) {
+	txn := &types.Transaction{
+		ID:         uuid.New(),
+		Timestamp:  time.Now(),
+		Operation:  types.OperationSetACL,
+		TargetPath: grant.TargetPath,
+		Entries: []types.ACLEntry{
+			{
+				EntityType: grant.EntityType,
+				Entity:     grant.Entity,
+				Action:     "remove",
+				IsDefault:  grant.IsDefault,
+			},
+		},
+		Recursive: grant.Recursive,
+		Status:    types.StatusPending,
+	}
+
+	if err := m.ScheduleBackgroundTransaction(grant.Username, txn); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to schedule removal of grant %s: %w", grant.ID, err)
+	}
+
+	if err := m.archivalPQ.SetGrantRevokeTxnPQ(context.Background(), postgresql.SetGrantRevokeTxnPQParams{
+		ID:          grant.ID,
+		RevokeTxnID: pgtype.UUID{Bytes: txn.ID, Valid: true},
+	}); err != nil {
+		m.errCh <- fmt.Errorf("failed to store revoking transaction of grant %s: %w", grant.ID, err)
+	}
+
+	return txn.ID, nil
+}
+
+/*
+revokes expired grants and sends expiry notices every interval until ctx is done
+grants are claimed with SKIP LOCKED, so several backends can share the grants table
+*/
+func (m *Manager) RunGrantReaper(ctx context.Context, interval, noticeBefore time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.reapExpiredGrants(ctx)
+			m.noticeExpiringGrants(ctx, noticeBefore)
+		}
+	}
+}
+
+/* schedules removal of every expired grant, whether or not the user who issued it is logged in */
+func (m *Manager) reapExpiredGrants(ctx context.Context) {
+	for {
+		rows, err := m.archivalPQ.ClaimExpiredGrantsPQ(ctx, grantClaimBatch)
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to claim expired grants: %w", err)
+			return
+		}
+
+		for _, row := range rows {
+			txnID, err := m.scheduleGrantRemoval(row)
+			if err != nil {
+				m.errCh <- err
+
+				/* put it back so the next round picks it up again */
+				if err := m.archivalPQ.ReactivateGrantPQ(ctx, row.ID); err != nil {
+					m.errCh <- fmt.Errorf("failed to reactivate grant %s: %w", row.ID, err)
+				}
+				continue
+			}
+
+			view := convertGrantToView(row)
+			view.RevokeTxnID = txnID.String()
+			if err := m.publishGrantNotice(row.Username, GrantNoticeExpired, view); err != nil {
+				m.errCh <- err
+			}
+		}
+
+		if len(rows) < grantClaimBatch {
+			return
+		}
+	}
+}
+
+/* sends a notice once for every grant expiring within noticeBefore */
+func (m *Manager) noticeExpiringGrants(ctx context.Context, noticeBefore time.Duration) {
+	var cutoff pgtype.Timestamptz
+	if err := cutoff.Scan(time.Now().Add(noticeBefore)); err != nil {
+		m.errCh <- fmt.Errorf("failed to convert grant notice cutoff: %w", err)
+		return
+	}
+
+	for {
+		rows, err := m.archivalPQ.ClaimGrantsNearingExpiryPQ(ctx, postgresql.ClaimGrantsNearingExpiryPQParams{
+			ExpiresAt: cutoff,
+			Limit:     grantClaimBatch,
+		})
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to claim grants nearing expiry: %w", err)
+			return
+		}
+
+		for _, row := range rows {
+			if err := m.publishGrantNotice(row.Username, GrantNoticeExpiring, convertGrantToView(row)); err != nil {
+				m.errCh <- err
+			}
+		}
+
+		if len(rows) < grantClaimBatch {
+			return
+		}
+	}
+}
+
+/* publishes a grant notice on user:<username>:notices, forwarded to the session websocket of the user */
+func (m *Manager) publishGrantNotice(username, notice string, grant GrantView) error {
+	payload, err := json.Marshal(GrantNotice{
+		Notice: notice,
+		Grant:  grant,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal grant notice: %w", err)
+	}
+
+	channel := fmt.Sprintf("user:%s:notices", username)
+	if err := m.redis.Publish(context.Background(), channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish grant notice: %w", err)
+	}
+
+	return nil
+}
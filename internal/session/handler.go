@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/PythonHacker24/linux-acl-management-backend/api/middleware"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
 )
 
 /*
@@ -40,37 +42,567 @@ func (m *Manager) IssueTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req types.ScheduleTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	/* retried requests with the same idempotency key get the originally scheduled transactions */
+	idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+	if len(idempotencyKey) > 255 {
+		http.Error(w, "Idempotency key is too long", http.StatusBadRequest)
+		return
+	}
+	resuming := false
+	if idempotencyKey != "" {
+		txnIDs, err := m.reserveIdempotencyKey(username, idempotencyKey, &req)
+		switch {
+		case errors.Is(err, ErrIdempotencyKeyPartial):
+			/* the targets scheduled by the original request are skipped below */
+			resuming = true
+		case errors.Is(err, ErrIdempotencyKeyMismatch):
+			http.Error(w, "Idempotency key was already used with a different request", http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, ErrIdempotencyKeyInProgress):
+			http.Error(w, "A request with this idempotency key is still being processed", http.StatusConflict)
+			return
+		case err != nil:
+			m.errCh <- fmt.Errorf("failed to reserve idempotency key: %w", err)
+			http.Error(w, "Failed to schedule transaction", http.StatusInternalServerError)
+			return
+		case txnIDs != nil:
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(http.StatusCreated)
+			if err := json.NewEncoder(w).Encode(scheduleResponse(&req, txnIDs)); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	/*
+		release the idempotency key if nothing got scheduled so the request can be retried
+		once a target is scheduled the key is kept, a partly scheduled request is resumed by its retry
+	*/
+	var txnIDs []string
+	defer func() {
+		if idempotencyKey != "" && !resuming && len(txnIDs) == 0 {
+			if err := m.releaseIdempotencyKey(username, idempotencyKey); err != nil {
+				m.errCh <- fmt.Errorf("failed to release idempotency key: %w", err)
+			}
+		}
+	}()
+
+	/* validate before taking the session lock, it may need LDAP and the filesystem servers */
+	if err := m.validator.ValidateRequest(&req); err != nil {
+		var fieldErrs validation.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"message": "Invalid transaction request",
+				"errors":  fieldErrs,
+			}); err != nil {
+				m.errCh <- fmt.Errorf("failed to encode validation errors: %w", err)
+			}
+			return
+		}
+
+		m.errCh <- fmt.Errorf("failed to validate transaction request: %w", err)
+		http.Error(w, "Failed to validate transaction request", http.StatusBadGateway)
+		return
+	}
+
+	/* high and urgent lanes are restricted to configured roles */
+	if err := m.validator.AuthorizePriority(username, req.Priority); err != nil {
+		if errors.Is(err, validation.ErrPriorityNotAllowed) {
+			http.Error(w, "Priority not allowed for user", http.StatusForbidden)
+			return
+		}
+		m.errCh <- fmt.Errorf("failed to authorize transaction priority: %w", err)
+		http.Error(w, "Failed to validate transaction request", http.StatusBadGateway)
+		return
+	}
+
 	/* acquire session lock for transaction operations */
 	session.Mutex.Lock()
 	defer session.Mutex.Unlock()
 
-	var req types.ScheduleTransactionRequest
+	/* copyacl is tracked per target, every other operation has a single target */
+	targets := []string{req.TargetPath}
+	if req.Operation == types.OperationCopyACL && len(req.TargetPaths) != 0 {
+		targets = req.TargetPaths
+	}
+
+	/* concurrent retries are serialized by the session lock, so the record read here is current */
+	txnIDs = make([]string, 0, len(targets))
+	if resuming {
+		record, err := m.getIdempotencyRecord(username, idempotencyKey)
+		if err != nil {
+			m.errCh <- err
+			http.Error(w, "Failed to schedule transaction", http.StatusInternalServerError)
+			return
+		}
+		txnIDs = append(txnIDs, record.TxnIDs...)
+
+		/* another retry finished the request meanwhile */
+		if !record.Partial || len(txnIDs) >= len(targets) {
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(http.StatusCreated)
+			if err := json.NewEncoder(w).Encode(scheduleResponse(&req, txnIDs)); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	/* keeps what was scheduled under the key before failing, a retry with the key picks up from there */
+	fail := func(message string) {
+		if idempotencyKey != "" && len(txnIDs) != 0 {
+			if err := m.completeIdempotencyKey(username, idempotencyKey, &req, txnIDs, true); err != nil {
+				m.errCh <- fmt.Errorf("failed to store idempotency key: %w", err)
+			}
+		}
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+
+	for _, target := range targets[len(txnIDs):] {
+		tx := types.Transaction{
+			ID:         uuid.New(),
+			SessionID:  session.ID,
+			Timestamp:  time.Now(),
+			Operation:  req.Operation,
+			TargetPath: target,
+			SourcePath: req.SourcePath,
+			Entries:    req.Entries,
+			Recursive:  req.Recursive,
+			ExecuteAt:  req.ExecuteAt,
+			Priority:   normalizePriority(req.Priority),
+			Status:     types.StatusPending,
+			ExecutedBy: username,
+		}
+
+		/* deferred transactions wait in PostgreSQL until they are due */
+		if req.ExecuteAt != nil {
+			if err := m.DeferTransaction(username, &tx); err != nil {
+				m.errCh <- err
+				fail("Failed to defer transaction")
+				return
+			}
+			txnIDs = append(txnIDs, tx.ID.String())
+			continue
+		}
+
+		/* add transaction to session - session lock is already held */
+		if err := m.AddTransaction(session, &tx); err != nil {
+			m.errCh <- err
+			fail("Failed to add transaction")
+			return
+		}
+
+		txnIDs = append(txnIDs, tx.ID.String())
+	}
+
+	if idempotencyKey != "" {
+		if err := m.completeIdempotencyKey(username, idempotencyKey, &req, txnIDs, false); err != nil {
+			m.errCh <- fmt.Errorf("failed to store idempotency key: %w", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(scheduleResponse(&req, txnIDs)); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for issuing a changeset (several paths changed as one unit) */
+func (m *Manager) IssueChangeset(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	/* acquire manager lock to access sessions map */
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	var req types.ScheduleChangesetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
+	/* validate before taking the session lock, it may need LDAP and the filesystem servers */
+	if err := m.validator.ValidateChangeset(&req); err != nil {
+		var fieldErrs validation.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"message": "Invalid changeset request",
+				"errors":  fieldErrs,
+			}); err != nil {
+				m.errCh <- fmt.Errorf("failed to encode validation errors: %w", err)
+			}
+			return
+		}
+
+		m.errCh <- fmt.Errorf("failed to validate changeset request: %w", err)
+		http.Error(w, "Failed to validate changeset request", http.StatusBadGateway)
+		return
+	}
+
+	/* high and urgent lanes are restricted to configured roles */
+	if err := m.validator.AuthorizePriority(username, req.Priority); err != nil {
+		if errors.Is(err, validation.ErrPriorityNotAllowed) {
+			http.Error(w, "Priority not allowed for user", http.StatusForbidden)
+			return
+		}
+		m.errCh <- fmt.Errorf("failed to authorize changeset priority: %w", err)
+		http.Error(w, "Failed to validate changeset request", http.StatusBadGateway)
+		return
+	}
+
+	/* the whole changeset is a single transaction, its ID is the changeset ID */
+	steps := make([]types.ChangesetStep, 0, len(req.Steps))
+	for _, step := range req.Steps {
+		steps = append(steps, types.ChangesetStep{
+			TargetPath: step.TargetPath,
+			Entries:    step.Entries,
+			Status:     types.StepPending,
+		})
+	}
+
+	/* acquire session lock for transaction operations */
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+
 	tx := types.Transaction{
 		ID:         uuid.New(),
 		SessionID:  session.ID,
 		Timestamp:  time.Now(),
-		Operation:  req.Operation,
-		TargetPath: req.TargetPath,
-		Entries:    req.Entries,
+		Operation:  types.OperationChangeset,
+		TargetPath: steps[0].TargetPath,
+		Entries:    []types.ACLEntry{},
+		Steps:      steps,
+		Priority:   normalizePriority(req.Priority),
 		Status:     types.StatusPending,
 		ExecutedBy: username,
 	}
 
 	/* add transaction to session - session lock is already held */
 	if err := m.AddTransaction(session, &tx); err != nil {
-		http.Error(w, "Failed to add transaction", http.StatusInternalServerError)
+		http.Error(w, "Failed to add changeset", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(map[string]string{
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"message":      "Changeset scheduled",
+		"changeset_id": tx.ID.String(),
+		"txn_id":       tx.ID.String(),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* response body of a scheduled request */
+func scheduleResponse(req *types.ScheduleTransactionRequest, txnIDs []string) map[string]any {
+	response := map[string]any{
 		"message": "Transaction scheduled",
-		"txn_id":  tx.ID.String(),
+		"txn_id":  txnIDs[0],
+	}
+	if req.Operation == types.OperationCopyACL {
+		response["txn_ids"] = txnIDs
+	}
+	if req.ExecuteAt != nil {
+		response["message"] = "Transaction deferred"
+		response["executeAt"] = req.ExecuteAt
+	}
+	return response
+}
+
+/* frontend safe handler for looking up a transaction by ID */
+func (m *Manager) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	txnID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	tx, source, err := m.FindTransaction(username, txnID)
+	if err != nil {
+		if errors.Is(err, ErrTransactionNotFound) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		m.errCh <- fmt.Errorf("failed to look up transaction %s: %w", txnID, err)
+		http.Error(w, "Failed to look up transaction", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ConvertTransactionToView(*tx, source)); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for listing deferred transactions of the user that are still waiting */
+func (m *Manager) ListDeferredTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	transactions, err := m.ListDeferredTransactions(username)
+	if err != nil {
+		m.errCh <- err
+		http.Error(w, "Failed to list deferred transactions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"transactions": transactions,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for listing transactions of the user left in the pending archive */
+func (m *Manager) ListRecoverableTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	transactions, err := m.ListRecoverableTransactions(username)
+	if err != nil {
+		m.errCh <- err
+		http.Error(w, "Failed to list recoverable transactions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"transactions": transactions,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for queueing a transaction from the pending archive into the session again */
+func (m *Manager) ReplayTransaction(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	txnID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := m.ReplayPendingTransaction(username, txnID); err != nil {
+		switch {
+		case errors.Is(err, ErrReplayDisabled):
+			http.Error(w, "Replay of pending transactions is disabled", http.StatusForbidden)
+		case errors.Is(err, ErrSessionNotFound):
+			http.Error(w, "Session not found", http.StatusNotFound)
+		case errors.Is(err, ErrTransactionNotFound):
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+		case errors.Is(err, ErrTransactionQueued):
+			http.Error(w, "Transaction is already queued", http.StatusConflict)
+		default:
+			m.errCh <- fmt.Errorf("failed to replay transaction %s: %w", txnID, err)
+			http.Error(w, "Failed to replay transaction", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"message": "Transaction replayed",
+		"txn_id":  txnID.String(),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for cancelling a transaction that is still deferred, queued or left in the pending archive */
+func (m *Manager) CancelTransaction(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	txnID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := m.CancelPendingTransaction(username, txnID); err != nil {
+		switch {
+		case errors.Is(err, ErrSessionNotFound):
+			http.Error(w, "Session not found", http.StatusNotFound)
+		case errors.Is(err, ErrTransactionNotFound):
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+		case errors.Is(err, ErrTransactionInProgress):
+			http.Error(w, "Transaction is already being processed", http.StatusConflict)
+		case errors.Is(err, ErrTransactionProcessed):
+			http.Error(w, "Transaction has already been processed", http.StatusConflict)
+		default:
+			m.errCh <- fmt.Errorf("failed to cancel transaction %s: %w", txnID, err)
+			http.Error(w, "Failed to cancel transaction", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"message": "Transaction cancelled",
+		"txn_id":  txnID.String(),
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for listing active time-bound grants issued by the user */
+func (m *Manager) ListGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	grants, err := m.ListGrants(username)
+	if err != nil {
+		m.errCh <- err
+		http.Error(w, "Failed to list grants", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"grants": grants,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for moving the expiry of a time-bound grant */
+func (m *Manager) ExtendGrantHandler(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	grantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ExtendGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := m.ExtendGrant(username, grantID, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidExpiry):
+			http.Error(w, "Expiry must be in the future", http.StatusBadRequest)
+		case errors.Is(err, ErrGrantNotFound):
+			http.Error(w, "Grant not found", http.StatusNotFound)
+		default:
+			m.errCh <- err
+			http.Error(w, "Failed to extend grant", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"message": "Grant extended",
+		"grant":   grant,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+/* frontend safe handler for revoking a time-bound grant before it expires */
+func (m *Manager) RevokeGrantHandler(w http.ResponseWriter, r *http.Request) {
+	/* extract username from JWT Token */
+	username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+
+	grantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := m.RevokeGrant(username, grantID)
+	if err != nil {
+		if errors.Is(err, ErrGrantNotFound) {
+			http.Error(w, "Grant not found", http.StatusNotFound)
+			return
+		}
+		m.errCh <- err
+		http.Error(w, "Failed to revoke grant", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"message": "Grant revocation scheduled",
+		"grant":   grant,
+		"txn_id":  grant.RevokeTxnID,
 	}); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
@@ -145,7 +677,7 @@ func (m *Manager) StreamUserSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	/* stream changes in session made in Redis */
-	go m.listenForSessionChanges(ctx, conn, sessionID)
+	go m.listenForSessionChanges(ctx, conn, username, sessionID)
 
 	/* specify the handler context */
 	ctxVal := context.WithValue(ctx, HandlerType, CtxStreamUserSession)
@@ -0,0 +1,136 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* header clients use to make retries of POST /transactions/schedule safe */
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+/* errors returned while reserving an idempotency key */
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still being processed")
+	ErrIdempotencyKeyPartial    = errors.New("request with this idempotency key was only partly scheduled")
+)
+
+/*
+record stored in Redis for an idempotency key
+TxnIDs is empty while the original request is still being scheduled
+a partial record holds the IDs of the targets scheduled before a failure (in target order), a retry schedules the rest
+*/
+type idempotencyRecord struct {
+	RequestHash string   `json:"requestHash"`
+	TxnIDs      []string `json:"txnIds,omitempty"`
+	Partial     bool     `json:"partial,omitempty"`
+}
+
+/* idempotency keys are scoped to the user so different users can't collide */
+func idempotencyRedisKey(username, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", username, key)
+}
+
+/* hashes the decoded request so formatting differences of the same body don't matter */
+func hashScheduleRequest(req *types.ScheduleTransactionRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+/*
+reserves an idempotency key for a request
+returns the transaction IDs of the original request if the key was used before with the same request
+ErrIdempotencyKeyPartial means the original request failed after scheduling some of its targets
+*/
+func (m *Manager) reserveIdempotencyKey(username, key string, req *types.ScheduleTransactionRequest) ([]string, error) {
+	ctx := context.Background()
+	ttl := time.Duration(config.BackendConfig.AppInfo.IdempotencyTTL) * time.Hour
+
+	hash, err := hashScheduleRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := json.Marshal(idempotencyRecord{RequestHash: hash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	redisKey := idempotencyRedisKey(username, key)
+	reserved, err := m.redis.SetNX(ctx, redisKey, record, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	/* key was used before, compare with the original request */
+	existing, err := m.getIdempotencyRecord(username, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.RequestHash != hash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if existing.Partial {
+		return nil, ErrIdempotencyKeyPartial
+	}
+	if len(existing.TxnIDs) == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing.TxnIDs, nil
+}
+
+/* reads the record stored for an idempotency key */
+func (m *Manager) getIdempotencyRecord(username, key string) (*idempotencyRecord, error) {
+	val, err := m.redis.Get(context.Background(), idempotencyRedisKey(username, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(val), &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+
+	return &record, nil
+}
+
+/*
+stores the transaction IDs scheduled for a reserved idempotency key
+partial marks a request that failed after scheduling these, the key is kept so a retry doesn't schedule them again
+*/
+func (m *Manager) completeIdempotencyKey(username, key string, req *types.ScheduleTransactionRequest, txnIDs []string, partial bool) error {
+	hash, err := hashScheduleRequest(req)
+	if err != nil {
+		return err
+	}
+
+	record, err := json.Marshal(idempotencyRecord{RequestHash: hash, TxnIDs: txnIDs, Partial: partial})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	ttl := time.Duration(config.BackendConfig.AppInfo.IdempotencyTTL) * time.Hour
+	return m.redis.Set(context.Background(), idempotencyRedisKey(username, key), record, ttl)
+}
+
+/* releases a reserved idempotency key so the request can be retried */
+func (m *Manager) releaseIdempotencyKey(username, key string) error {
+	return m.redis.Del(context.Background(), idempotencyRedisKey(username, key)).Err()
+}
@@ -0,0 +1,348 @@
+package session
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/api/middleware"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
+)
+
+/* every path exists */
+type stubPaths struct{}
+
+func (stubPaths) CheckPath(targetPath string) error { return nil }
+
+/* every user and group exists, users aren't in any group */
+type stubDirectory struct{}
+
+func (stubDirectory) UserExists(name string) (bool, error)         { return true, nil }
+func (stubDirectory) GroupExists(name string) (bool, error)        { return true, nil }
+func (stubDirectory) UserGroups(username string) ([]string, error) { return nil, nil }
+
+var errFakeInsert = errors.New("insert failed")
+
+/* copies the ACL of one path to three targets, one transaction per target */
+func copyRequest() *types.ScheduleTransactionRequest {
+	return &types.ScheduleTransactionRequest{
+		Operation:   types.OperationCopyACL,
+		SourcePath:  "/srv/src",
+		TargetPaths: []string{"/srv/a", "/srv/b", "/srv/c"},
+	}
+}
+
+/* stores an idempotency record for alice as if an earlier request had left it */
+func storeRecord(t *testing.T, m *Manager, key string, req *types.ScheduleTransactionRequest, txnIDs []string, partial bool) {
+	hash, err := hashScheduleRequest(req)
+	if err != nil {
+		t.Fatalf("hash request: %v", err)
+	}
+	record, err := json.Marshal(idempotencyRecord{RequestHash: hash, TxnIDs: txnIDs, Partial: partial})
+	if err != nil {
+		t.Fatalf("marshal record: %v", err)
+	}
+	if err := m.redis.Set(context.Background(), idempotencyRedisKey("alice", key), record, 0); err != nil {
+		t.Fatalf("store record: %v", err)
+	}
+}
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	other := copyRequest()
+	other.TargetPaths = []string{"/srv/a"}
+
+	tests := []struct {
+		name string
+
+		/* record left by an earlier request, none if nil */
+		stored        *types.ScheduleTransactionRequest
+		storedTxnIDs  []string
+		storedPartial bool
+
+		wantTxnIDs []string
+		wantErr    error
+	}{
+		{
+			name: "unused key is reserved",
+		},
+		{
+			name:         "replayed key returns the scheduled transactions",
+			stored:       copyRequest(),
+			storedTxnIDs: []string{"1", "2", "3"},
+			wantTxnIDs:   []string{"1", "2", "3"},
+		},
+		{
+			name:         "key used with a different request",
+			stored:       other,
+			storedTxnIDs: []string{"1"},
+			wantErr:      ErrIdempotencyKeyMismatch,
+		},
+		{
+			name:    "key of a request still being scheduled",
+			stored:  copyRequest(),
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name:          "key of a partly scheduled request",
+			stored:        copyRequest(),
+			storedTxnIDs:  []string{"1"},
+			storedPartial: true,
+			wantErr:       ErrIdempotencyKeyPartial,
+		},
+		{
+			name:          "partial record of a different request",
+			stored:        other,
+			storedTxnIDs:  []string{"1"},
+			storedPartial: true,
+			wantErr:       ErrIdempotencyKeyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, rdb, _, _ := newFakeManager()
+			if tt.stored != nil {
+				storeRecord(t, m, "key", tt.stored, tt.storedTxnIDs, tt.storedPartial)
+			}
+
+			txnIDs, err := m.reserveIdempotencyKey("alice", "key", copyRequest())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !equalStrings(txnIDs, tt.wantTxnIDs) {
+				t.Errorf("txn IDs = %v, want %v", txnIDs, tt.wantTxnIDs)
+			}
+
+			/* a reserved key holds a record without transactions until the request is scheduled */
+			if tt.stored == nil {
+				record, err := m.getIdempotencyRecord("alice", "key")
+				if err != nil {
+					t.Fatalf("reserved key has no record: %v", err)
+				}
+				if len(record.TxnIDs) != 0 || record.Partial {
+					t.Errorf("reserved record = %+v", record)
+				}
+			}
+
+			/* keys of other users aren't touched */
+			if _, err := rdb.Get(context.Background(), idempotencyRedisKey("bob", "key")); err == nil {
+				t.Errorf("key reserved for another user")
+			}
+		})
+	}
+}
+
+/* manager with an active session of alice */
+func newIdempotencyManager(t *testing.T) (*Manager, *fakeDB) {
+	m, _, db, errCh := newFakeManager()
+	m.validator = validation.NewValidator(stubPaths{}, stubDirectory{})
+
+	session := &Session{ID: uuid.New(), Username: "alice", TransactionQueue: list.New()}
+	m.sessionsMap[session.Username] = session
+	m.sessionOrder.PushBack(session)
+
+	/* errors are expected while inserts fail, they are only drained */
+	t.Cleanup(func() {
+		for len(errCh) != 0 {
+			<-errCh
+		}
+	})
+
+	return m, db
+}
+
+/* response to POST /transactions/schedule */
+type scheduleResult struct {
+	status   int
+	replayed bool
+	txnIDs   []string
+}
+
+/* issues a request for alice through the handler */
+func issue(t *testing.T, m *Manager, key string, req *types.ScheduleTransactionRequest) scheduleResult {
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/transactions/schedule", bytes.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), middleware.ContextKeyUsername, "alice"))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	m.IssueTransaction(w, r)
+
+	result := scheduleResult{status: w.Code, replayed: w.Header().Get("Idempotent-Replayed") == "true"}
+	if w.Code == http.StatusCreated {
+		var response struct {
+			TxnIDs []string `json:"txn_ids"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		result.txnIDs = response.TxnIDs
+	}
+
+	return result
+}
+
+/* transactions written to the durable queue */
+func queuedCount(db *fakeDB) int {
+	return countQueued(db.statements())
+}
+
+func countQueued(execs []fakeExec) int {
+	count := 0
+	for _, stmt := range execs {
+		if strings.Contains(stmt.sql, "INSERT INTO queued_transactions") {
+			count++
+		}
+	}
+	return count
+}
+
+/* inserts into the durable queue fail after the given number succeeded, the check runs under the database lock */
+func failQueueAfter(db *fakeDB, succeeded int) {
+	db.failWith(func(sql string) error {
+		if strings.Contains(sql, "INSERT INTO queued_transactions") && countQueued(db.execs) >= succeeded {
+			return errFakeInsert
+		}
+		return nil
+	})
+}
+
+func TestIssueTransactionReplay(t *testing.T) {
+	m, db := newIdempotencyManager(t)
+
+	first := issue(t, m, "key", copyRequest())
+	if first.status != http.StatusCreated || first.replayed || len(first.txnIDs) != 3 {
+		t.Fatalf("first request = %+v", first)
+	}
+
+	retry := issue(t, m, "key", copyRequest())
+	if retry.status != http.StatusCreated || !retry.replayed {
+		t.Fatalf("retry = %+v, want a replayed response", retry)
+	}
+	if !equalStrings(retry.txnIDs, first.txnIDs) {
+		t.Errorf("retry scheduled %v, want %v", retry.txnIDs, first.txnIDs)
+	}
+	if n := queuedCount(db); n != 3 {
+		t.Errorf("%d transactions queued, want 3", n)
+	}
+
+	/* the key is bound to the request it was used with */
+	other := copyRequest()
+	other.SourcePath = "/srv/other"
+	if got := issue(t, m, "key", other); got.status != http.StatusUnprocessableEntity {
+		t.Errorf("different request with the key = %d, want %d", got.status, http.StatusUnprocessableEntity)
+	}
+
+	/* requests without a key are never replayed */
+	if got := issue(t, m, "", copyRequest()); got.status != http.StatusCreated || got.replayed {
+		t.Errorf("request without key = %+v", got)
+	}
+}
+
+func TestIssueTransactionInProgress(t *testing.T) {
+	m, db := newIdempotencyManager(t)
+	storeRecord(t, m, "key", copyRequest(), nil, false)
+
+	if got := issue(t, m, "key", copyRequest()); got.status != http.StatusConflict {
+		t.Errorf("status = %d, want %d", got.status, http.StatusConflict)
+	}
+	if n := queuedCount(db); n != 0 {
+		t.Errorf("%d transactions queued, want none", n)
+	}
+
+	/* the original request still owns the key */
+	record, err := m.getIdempotencyRecord("alice", "key")
+	if err != nil {
+		t.Fatalf("key was released: %v", err)
+	}
+	if len(record.TxnIDs) != 0 {
+		t.Errorf("record = %+v", record)
+	}
+}
+
+func TestIssueTransactionResume(t *testing.T) {
+	tests := []struct {
+		name string
+
+		/* inserts into the durable queue that succeed during the first request */
+		succeeded int
+
+		/* the key is kept for a partly scheduled request and released if nothing was scheduled */
+		wantKept bool
+	}{
+		{name: "failure before any target", succeeded: 0, wantKept: false},
+		{name: "failure after the first target", succeeded: 1, wantKept: true},
+		{name: "failure before the last target", succeeded: 2, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db := newIdempotencyManager(t)
+
+			failQueueAfter(db, tt.succeeded)
+			if got := issue(t, m, "key", copyRequest()); got.status != http.StatusInternalServerError {
+				t.Fatalf("failing request = %d, want %d", got.status, http.StatusInternalServerError)
+			}
+
+			record, err := m.getIdempotencyRecord("alice", "key")
+			if !tt.wantKept {
+				if err == nil {
+					t.Fatalf("key kept with %+v, want it released", record)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("key wasn't kept: %v", err)
+				}
+				if !record.Partial || len(record.TxnIDs) != tt.succeeded {
+					t.Fatalf("record = %+v, want %d partly scheduled transactions", record, tt.succeeded)
+				}
+			}
+
+			/* the retry schedules the remaining targets only */
+			db.failWith(nil)
+			retry := issue(t, m, "key", copyRequest())
+			if retry.status != http.StatusCreated || len(retry.txnIDs) != 3 {
+				t.Fatalf("retry = %+v", retry)
+			}
+			if tt.wantKept && !equalStrings(retry.txnIDs[:tt.succeeded], record.TxnIDs) {
+				t.Errorf("retry scheduled %v, want it to start with %v", retry.txnIDs, record.TxnIDs)
+			}
+			if n := queuedCount(db); n != 3 {
+				t.Errorf("%d transactions queued, want 3", n)
+			}
+
+			/* the record is complete, a further retry is replayed */
+			again := issue(t, m, "key", copyRequest())
+			if !again.replayed || !equalStrings(again.txnIDs, retry.txnIDs) {
+				t.Errorf("further retry = %+v, want a replay of %v", again, retry.txnIDs)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -3,10 +3,14 @@ package session
 import (
 	"container/list"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/PythonHacker24/linux-acl-management-backend/config"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
@@ -68,6 +72,11 @@ func (m *Manager) CreateSession(username, ipAddress, userAgent string) (uuid.UUI
 		return uuid.Nil, fmt.Errorf("failed to store session to Redis")
 	}
 
+	/* transactions left behind by earlier sessions are queued again (manager lock is held here) */
+	if config.BackendConfig.Replay.Mode == config.ReplayEnqueue {
+		go m.replayPendingTransactions(username)
+	}
+
 	return sessionID, nil
 }
 
@@ -103,6 +112,9 @@ func (m *Manager) ExpireSession(username string) error {
 				continue
 			}
 
+			/* the transaction leaves the backend, later ones on its paths mustn't wait for it */
+			m.releasePaths(txResult.ID)
+
 			/* make sure to set status to pending (shouldn't it be already set?) */
 			txResult.Status = types.StatusPending
 
@@ -128,6 +140,9 @@ func (m *Manager) ExpireSession(username string) error {
 				m.errCh <- fmt.Errorf("failed to archive transaction %s after retries: %w", txResult.ID, storeErr)
 				continue
 			}
+
+			/* the pending archive holds the transaction from now on */
+			m.ackQueuedTransaction(txResult)
 		}
 
 		/* mark session as pending */
@@ -143,15 +158,14 @@ func (m *Manager) ExpireSession(username string) error {
 		m.errCh <- fmt.Errorf("failed to get transaction results from Redis: %w", err)
 	} else {
 		for _, txResult := range results {
+			/* results are archived when processed, only those that couldn't be stored then are left */
+			if txResult.Archived {
+				continue
+			}
 			if txResult.Status == types.StatusSuccess || txResult.Status == types.StatusFailed {
-				pqParams, err := ConvertTransactionResulttoStoreParams(txResult)
-				if err != nil {
-					m.errCh <- fmt.Errorf("failed to convert transaction result to archive format: %w", err)
-					continue
-				}
 				var storeErr error
 				for retries := 0; retries < 3; retries++ {
-					if _, err := m.archivalPQ.CreateResultsTransactionPQ(context.Background(), pqParams); err != nil {
+					if err := m.storeTransactionResult(&txResult); err != nil {
 						storeErr = err
 						time.Sleep(time.Second * time.Duration(retries+1))
 						continue
@@ -194,10 +208,11 @@ func (m *Manager) ExpireSession(username string) error {
 		m.errCh <- fmt.Errorf("failed to convert session to archive format: %w", err)
 	}
 
-	/* delete both session and transaction results from Redis */
+	/* delete session, pending transactions and transaction results from Redis */
 	sessionKey := fmt.Sprintf("session:%s", session.ID)
+	txPendingKey := fmt.Sprintf("session:%s:txnpending", session.ID)
 	txResultsKey := fmt.Sprintf("session:%s:txresults", session.ID)
-	result := m.redis.Del(context.Background(), sessionKey, txResultsKey)
+	result := m.redis.Del(context.Background(), sessionKey, txPendingKey, txResultsKey)
 	if result.Err() != nil {
 		m.errCh <- fmt.Errorf("failed to delete session from Redis: %w", result.Err())
 	}
@@ -210,17 +225,388 @@ func (m *Manager) ExpireSession(username string) error {
 
 /* add transaction to a session - assumes caller holds necessary locks */
 func (m *Manager) AddTransaction(session *Session, txn *types.Transaction) error {
+	/* empty and unknown priorities are stored as normal, before the transaction is persisted */
+	txn.Priority = normalizePriority(txn.Priority)
+
+	/* the durable queue comes first, a transaction is never only in memory */
+	if err := m.persistQueuedTransaction(session.Username, txn); err != nil {
+		return err
+	}
+
+	/* order against transactions on the same paths is fixed from here on */
+	m.gate.admit(txn)
+
 	/* push transaction into the queue from back */
-	session.TransactionQueue.PushBack(txn)
+	node := session.TransactionQueue.PushBack(txn)
+	m.gate.enqueue(session.ID, txn, node)
+	m.notifyScheduler()
 
-	/* store transaction to Redis as a pending transaction */
+	/*
+		store transaction to Redis as a pending transaction
+		it is queued and durable by now, so a failure here only affects the pending view and isn't returned
+	*/
 	if err := m.SavePendingTransaction(session, txn); err != nil {
-		return fmt.Errorf("failed to save transaction to Redis: %w", err)
+		m.errCh <- fmt.Errorf("failed to save transaction %s to Redis: %w", txn.ID, err)
 	}
 
 	return nil
 }
 
+/*
+updates session state after a transaction was processed
+counts it as completed or failed, moves it from pending to results in Redis and tracks time-bound grants
+*/
+func (m *Manager) CompleteTransaction(session *Session, txn *types.Transaction) {
+	/* the filesystem servers of the transaction can take the next one */
+	m.releaseServers(txn)
+
+	/* update the session's completed/failed count */
+	session.Mutex.Lock()
+	if txn.ExecStatus {
+		session.CompletedCount++
+		if err := m.IncrementSessionCompletedRedis(session); err != nil {
+			m.errCh <- err
+		}
+	} else {
+		session.FailedCount++
+		if err := m.IncrementSessionFailedRedis(session); err != nil {
+			m.errCh <- err
+		}
+	}
+	session.Mutex.Unlock()
+
+	/* the queue entry is acknowledged once the result is archived, otherwise it is archived with the session */
+	if err := m.storeTransactionResult(txn); err != nil {
+		m.errCh <- err
+	}
+
+	/* store the result of processed transaction into Redis */
+	if err := m.SaveTransactionRedisList(session, txn, "txresults"); err != nil {
+		m.errCh <- fmt.Errorf("failed to store processed transaction %s into Redis: %w", txn.ID, err)
+	}
+
+	/* remove the transaction as pending from Redis */
+	if err := m.RemovePendingTransaction(session, txn.ID); err != nil {
+		m.errCh <- fmt.Errorf("failed to remove pending transaction %s from Redis: %w", txn.ID, err)
+	}
+
+	/* time-bound grants are revoked by the grant reaper, a failed revocation is retried */
+	m.recordGrants(txn)
+	m.rearmGrant(txn)
+
+	/* transactions waiting on the paths of this one may run now */
+	m.releasePaths(txn.ID)
+}
+
+/*
+puts a transaction that failed with a transient error back into the session queue after delay
+it stays pending in Redis while waiting; if the session expires meanwhile, it is archived as pending
+*/
+func (m *Manager) RequeueTransaction(session *Session, txn *types.Transaction, delay time.Duration) error {
+	/* a waiting transaction doesn't hold a slot on its filesystem servers */
+	m.releaseServers(txn)
+
+	session.Mutex.Lock()
+	txn.Status = types.StatusPending
+	err := m.SavePendingTransaction(session, txn)
+	session.Mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update pending transaction in Redis: %w", err)
+	}
+
+	/* back to queued in PostgreSQL, attempts and last error included */
+	if err := m.persistQueuedTransaction(session.Username, txn); err != nil {
+		return err
+	}
+
+	time.AfterFunc(delay, func() {
+		/* same lock order as ExpireSession, the session can't expire in between */
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+
+		session.Mutex.Lock()
+		defer session.Mutex.Unlock()
+
+		if m.sessionsMap[session.Username] == session {
+			node := session.TransactionQueue.PushBack(txn)
+			m.gate.enqueue(session.ID, txn, node)
+			m.notifyScheduler()
+			return
+		}
+
+		/* session is gone, keep the transaction in the pending archive */
+		m.releasePaths(txn.ID)
+
+		txnPQ, err := ConvertTransactionPendingtoStoreParams(*txn)
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to convert requeued transaction to pending archive format: %w", err)
+			return
+		}
+		if _, err := m.archivalPQ.CreatePendingTransactionPQ(context.Background(), txnPQ); err != nil {
+			m.errCh <- fmt.Errorf("failed to archive requeued transaction %s: %w", txn.ID, err)
+			return
+		}
+
+		m.ackQueuedTransaction(txn)
+	})
+
+	return nil
+}
+
+/* schedules a transaction built by the backend into the active session of a user */
+func (m *Manager) ScheduleTransaction(username string, txn *types.Transaction) error {
+	/* acquire manager lock to access sessions map */
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session == nil {
+		return ErrSessionNotFound
+	}
+
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+
+	txn.SessionID = session.ID
+	txn.ExecutedBy = username
+
+	return m.AddTransaction(session, txn)
+}
+
+/*
+cancels a transaction of a user that is still deferred, waiting in the session queue or in the pending archive
+the transaction is archived as cancelled and an event is published for the pending transactions websocket
+*/
+func (m *Manager) CancelPendingTransaction(username string, txnID uuid.UUID) (*types.Transaction, error) {
+	/* deferred transactions wait in PostgreSQL, not in a session queue */
+	if tx, err := m.cancelDeferredTransaction(username, txnID); !errors.Is(err, ErrTransactionNotFound) {
+		return tx, err
+	}
+
+	/* acquire manager lock to access sessions map */
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session != nil {
+		tx, err := m.cancelQueuedTransaction(session, txnID)
+		if err == nil || !errors.Is(err, ErrTransactionNotFound) {
+			return tx, err
+		}
+	}
+
+	/* transactions left behind by an expired session wait in the pending archive */
+	if tx, err := m.discardArchivedTransaction(username, txnID); !errors.Is(err, ErrTransactionNotFound) {
+		return tx, err
+	}
+
+	/* not queued anymore; tell apart processed transactions from unknown ones */
+	if _, err := m.FindTransactionResult(username, txnID); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrTransactionProcessed
+}
+
+/* removes a transaction from the session queue and Redis under the session lock */
+func (m *Manager) cancelQueuedTransaction(session *Session, txnID uuid.UUID) (*types.Transaction, error) {
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+
+	var (
+		node *list.Element
+		tx   *types.Transaction
+	)
+	for n := session.TransactionQueue.Front(); n != nil; n = n.Next() {
+		if queued, ok := n.Value.(*types.Transaction); ok && queued.ID == txnID {
+			node, tx = n, queued
+			break
+		}
+	}
+
+	if node == nil {
+		/* a worker removes the transaction from the queue but it stays pending in Redis until processed */
+		pending, err := m.getPendingTransactionsRedis(session.ID.String(), 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pending {
+			if p.ID == txnID {
+				return nil, ErrTransactionInProgress
+			}
+		}
+		return nil, ErrTransactionNotFound
+	}
+
+	/* archive first, the transaction stays queued if it can't be recorded */
+	cancelled := *tx
+	cancelled.Status = types.StatusCancelled
+	cancelled.Output = "Transaction cancelled by user"
+
+	/* a replayed transaction leaves the pending archive too, it must not be replayed again */
+	if err := m.storeTransactionResult(&cancelled); err != nil {
+		return nil, err
+	}
+
+	session.TransactionQueue.Remove(node)
+	m.releasePaths(txnID)
+
+	/* a grant stays in place until its removal succeeds */
+	m.rearmGrant(&cancelled)
+
+	if err := m.RemovePendingTransaction(session, txnID); err != nil {
+		m.errCh <- fmt.Errorf("failed to remove cancelled transaction %s from Redis: %w", txnID, err)
+	}
+
+	/* keep it with the session results so it is visible until the session expires */
+	if err := m.SaveTransactionRedisList(session, &cancelled, "txresults"); err != nil {
+		m.errCh <- fmt.Errorf("failed to store cancelled transaction %s in Redis: %w", txnID, err)
+	}
+
+	if err := m.publishTransactionEvent(session, TxnEventCancelled, &cancelled); err != nil {
+		m.errCh <- fmt.Errorf("failed to publish cancellation of transaction %s: %w", txnID, err)
+	}
+
+	return &cancelled, nil
+}
+
+/*
+finds a processed transaction of a user
+results of the active session are looked up in Redis first, then the archive in PostgreSQL
+*/
+func (m *Manager) FindTransactionResult(username string, txnID uuid.UUID) (*types.Transaction, error) {
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session != nil {
+		results, err := m.getTransactionResultsRedis(session, 10000)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			if results[i].ID == txnID {
+				return &results[i], nil
+			}
+		}
+	}
+
+	row, err := m.archivalPQ.GetResultsTransactionPQ(context.Background(), txnID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction result from archive: %w", err)
+	}
+
+	/* transactions of other users are treated as if they don't exist */
+	if row.ExecutedBy != username {
+		return nil, ErrTransactionNotFound
+	}
+
+	tx, err := ConvertResultsArchiveToTransaction(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+/*
+finds any transaction of a user and reports where it was found
+the pending hash and results list of the active session in Redis are checked first,
+then the pending and results archives and the deferred queue in PostgreSQL
+*/
+func (m *Manager) FindTransaction(username string, txnID uuid.UUID) (*types.Transaction, string, error) {
+	ctx := context.Background()
+
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session != nil {
+		key := fmt.Sprintf("session:%s:txnpending", session.ID)
+		val, err := m.redis.HGet(ctx, key, txnID.String()).Result()
+		switch {
+		case err == nil:
+			var tx types.Transaction
+			if err := json.Unmarshal([]byte(val), &tx); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal pending transaction: %w", err)
+			}
+			return &tx, TxnSourcePending, nil
+		case !errors.Is(err, redis.Nil):
+			return nil, "", fmt.Errorf("failed to get pending transaction: %w", err)
+		}
+
+		results, err := m.getTransactionResultsRedis(session, 10000)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range results {
+			if results[i].ID == txnID {
+				return &results[i], TxnSourceResults, nil
+			}
+		}
+	}
+
+	/* transactions of other users are treated as if they don't exist */
+	pendingRow, err := m.archivalPQ.GetPendingTransactionPQ(ctx, txnID)
+	switch {
+	case err == nil:
+		if pendingRow.ExecutedBy != username {
+			return nil, "", ErrTransactionNotFound
+		}
+		tx, err := ConvertPendingArchiveToTransaction(pendingRow)
+		if err != nil {
+			return nil, "", err
+		}
+		return &tx, TxnSourcePendingArchive, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, "", fmt.Errorf("failed to get transaction from pending archive: %w", err)
+	}
+
+	resultsRow, err := m.archivalPQ.GetResultsTransactionPQ(ctx, txnID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return m.findDeferredTransaction(username, txnID)
+		}
+		return nil, "", fmt.Errorf("failed to get transaction from results archive: %w", err)
+	}
+	if resultsRow.ExecutedBy != username {
+		return nil, "", ErrTransactionNotFound
+	}
+
+	tx, err := ConvertResultsArchiveToTransaction(resultsRow)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &tx, TxnSourceResultsArchive, nil
+}
+
+/* finds a deferred transaction of a user that wasn't dispatched yet */
+func (m *Manager) findDeferredTransaction(username string, txnID uuid.UUID) (*types.Transaction, string, error) {
+	row, err := m.archivalPQ.GetDeferredTransactionPQ(context.Background(), txnID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", ErrTransactionNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get deferred transaction: %w", err)
+	}
+
+	/* dispatched and cancelled ones are found in Redis or the archives */
+	if row.Username != username || row.Status != "scheduled" {
+		return nil, "", ErrTransactionNotFound
+	}
+
+	var tx types.Transaction
+	if err := json.Unmarshal(row.Transaction, &tx); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal deferred transaction: %w", err)
+	}
+
+	return &tx, TxnSourceDeferred, nil
+}
+
 /* refresh the session timer */
 func (m *Manager) RefreshTimer(username string) error {
 	/* get session from sessionMap */
@@ -4,9 +4,15 @@ import (
 	"container/list"
 	"net/http"
 	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/redis"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
 	"github.com/gorilla/websocket"
 )
 
@@ -30,19 +36,37 @@ type Manager struct {
 	mutex        sync.RWMutex
 	redis        redis.RedisClient
 	archivalPQ   *postgresql.Queries
+	validator    *validation.Validator
 	errCh        chan<- error
 	upgrader     websocket.Upgrader
+
+	/* owner of the transactions this backend queued in PostgreSQL */
+	instanceID uuid.UUID
+
+	/* keeps transactions on the same path in submission order */
+	gate *pathGate
+
+	/* parks transactions of busy filesystem servers and daemons with an open circuit */
+	servers *serverGate
+
+	/* signals the scheduler that a transaction may be ready for dispatch, buffered so no signal is lost */
+	wakeup chan struct{}
 }
 
 /* create a new session manager */
-func NewManager(redis redis.RedisClient, archivalPQ *postgresql.Queries, errCh chan<- error) *Manager {
+func NewManager(redis redis.RedisClient, archivalPQ *postgresql.Queries, validator *validation.Validator, circuits CircuitChecker, errCh chan<- error) *Manager {
 	return &Manager{
 		sessionsMap:  make(map[string]*Session),
 		sessionOrder: list.New(),
 		redis:        redis,
 		archivalPQ:   archivalPQ,
+		validator:    validator,
 		errCh:        errCh,
 		upgrader:     customupgrader,
+		instanceID:   uuid.New(),
+		gate:         newPathGate(),
+		servers:      newServerGate(circuits),
+		wakeup:       make(chan struct{}, 1),
 	}
 }
 
@@ -62,3 +86,78 @@ func (m *Manager) GetNextSession() *Session {
 	m.sessionOrder.MoveToBack(element)
 	return session
 }
+
+/*
+removes the oldest transaction of a session that is ready for dispatch in at least the given lane from its queue
+transactions waiting for an older transaction on the same path or for their filesystem server are skipped, nil if none is ready
+the transaction holds a slot on its filesystem servers until it is completed or requeued
+*/
+func (m *Manager) PopReadyTransaction(session *Session, lane int) *types.Transaction {
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+
+	txn, node := m.gate.popReady(session.ID, lane, time.Now(), m.servers)
+	if txn == nil {
+		return nil
+	}
+
+	session.TransactionQueue.Remove(node)
+	return txn
+}
+
+/* active sessions in round robin order, a snapshot for schedulers that pick sessions themselves */
+func (m *Manager) Sessions() []*Session {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	sessions := make([]*Session, 0, m.sessionOrder.Len())
+	for element := m.sessionOrder.Front(); element != nil; element = element.Next() {
+		sessions = append(sessions, element.Value.(*Session))
+	}
+
+	return sessions
+}
+
+/* number of active sessions */
+func (m *Manager) SessionCount() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.sessionOrder.Len()
+}
+
+/*
+channel receiving a signal whenever a transaction may have become ready for dispatch
+schedulers sleep on it once no session has work for them
+*/
+func (m *Manager) Wakeup() <-chan struct{} {
+	return m.wakeup
+}
+
+/* wakes up the scheduler, a pending signal covers this one as well */
+func (m *Manager) notifyScheduler() {
+	select {
+	case m.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+/* called when the circuit of a daemon half-opens or closes, its parked transactions can be dispatched again */
+func (m *Manager) ServerRecovered(addr string) {
+	zap.L().Info("Dispatching parked transactions of recovered daemon",
+		zap.String("Address", addr),
+	)
+	m.notifyScheduler()
+}
+
+/* returns the filesystem server slots of a processed transaction and wakes up transactions parked on them */
+func (m *Manager) releaseServers(txn *types.Transaction) {
+	m.servers.release(txn)
+	m.notifyScheduler()
+}
+
+/* removes the ticket of a transaction from the path gate and wakes up transactions waiting on it */
+func (m *Manager) releasePaths(txnID uuid.UUID) {
+	m.gate.release(txnID)
+	m.notifyScheduler()
+}
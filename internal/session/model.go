@@ -2,10 +2,13 @@ package session
 
 import (
 	"container/list"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
 )
 
 /* defining Status type for sessions */
@@ -18,6 +21,20 @@ const (
 	StatusPending Status = "pending"
 )
 
+/* errors returned while looking up sessions and transactions */
+var (
+	ErrSessionNotFound     = errors.New("active user session not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	/* returned when cancelling a transaction that left the queue */
+	ErrTransactionInProgress = errors.New("transaction is already being processed")
+	ErrTransactionProcessed  = errors.New("transaction has already been processed")
+
+	/* returned when replaying a transaction from the pending archive */
+	ErrTransactionQueued = errors.New("transaction is already queued")
+	ErrReplayDisabled    = errors.New("replay of pending transactions is disabled")
+)
+
 /*
 session struct for a user
 appropriate fields must always be updated when any request is made
@@ -109,8 +126,102 @@ type ACLEntryStream struct {
 	Error       string `json:"error,omitempty"`
 }
 
+/* event published on session:<sessionID>:txnevents and forwarded to the pending transactions websocket */
+type TransactionEvent struct {
+	Event       string             `json:"event"`
+	TxnID       string             `json:"txn_id"`
+	Transaction *types.Transaction `json:"transaction,omitempty"`
+}
+
+/* transaction events */
+const (
+	TxnEventCancelled = "cancelled"
+)
+
+/* where a looked up transaction was found */
+const (
+	TxnSourcePending        = "pending"
+	TxnSourceResults        = "results"
+	TxnSourcePendingArchive = "pending_archive"
+	TxnSourceResultsArchive = "results_archive"
+	TxnSourceDeferred       = "deferred"
+)
+
+/* TransactionView is a unified, frontend-safe view of a transaction wherever it is stored */
+type TransactionView struct {
+	ID         string                `json:"id"`
+	Source     string                `json:"source"`
+	Operation  string                `json:"operation"`
+	TargetPath string                `json:"targetPath"`
+	SourcePath string                `json:"sourcePath,omitempty"`
+	Recursive  bool                  `json:"recursive"`
+	Priority   string                `json:"priority"`
+	Status     string                `json:"status"`
+	ExecStatus bool                  `json:"execStatus"`
+	Output     string                `json:"output"`
+	ErrorMsg   string                `json:"errorMsg,omitempty"`
+	Warnings   []string              `json:"warnings,omitempty"`
+	Failures   []types.PathFailure   `json:"failures,omitempty"`
+	Entries    []types.ACLEntry      `json:"entries"`
+	Steps      []types.ChangesetStep `json:"steps,omitempty"`
+	ExecutedBy string                `json:"executedBy"`
+	Timestamp  time.Time             `json:"timestamp"`
+	DurationMs int64                 `json:"durationMs"`
+	Attempts   int                   `json:"attempts"`
+	LastError  string                `json:"lastError,omitempty"`
+	Replayed   bool                  `json:"replayed,omitempty"`
+}
+
+/* GrantView is a frontend-safe representation of a time-bound grant */
+type GrantView struct {
+	ID          string    `json:"id"`
+	TxnID       string    `json:"txnId"`
+	TargetPath  string    `json:"targetPath"`
+	EntityType  string    `json:"entityType"`
+	Entity      string    `json:"entity"`
+	Permissions string    `json:"permissions"`
+	IsDefault   bool      `json:"isDefault"`
+	Recursive   bool      `json:"recursive"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Status      string    `json:"status"`
+	RevokeTxnID string    `json:"revokeTxnId,omitempty"`
+}
+
+/* notice published on user:<username>:notices and forwarded to the session websocket */
+type GrantNotice struct {
+	Notice string    `json:"notice"`
+	Grant  GrantView `json:"grant"`
+}
+
+/* request body for extending a grant */
+type ExtendGrantRequest struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 /* archival data fetch requests */
 type ArchivalRequest struct {
 	Limit  int32 `json:"limit"`
 	Offset int32 `json:"offset"`
 }
+
+/* queue depth of a session, reported by the scheduler introspection endpoint */
+type SessionQueueStats struct {
+	SessionID string `json:"sessionId"`
+	Username  string `json:"username"`
+	Depth     int    `json:"depth"`
+
+	/* transactions that could be dispatched right now (path and server gates permitting) */
+	Ready int `json:"ready"`
+}
+
+/* oldest transaction waiting in any session queue */
+type PendingTransactionStats struct {
+	ID         string              `json:"id"`
+	SessionID  string              `json:"sessionId"`
+	Username   string              `json:"username"`
+	Operation  types.OperationType `json:"operation"`
+	TargetPath string              `json:"targetPath"`
+	Priority   types.Priority      `json:"priority"`
+	QueuedAt   time.Time           `json:"queuedAt"`
+	WaitingMs  int64               `json:"waitingMs"`
+}
@@ -0,0 +1,400 @@
+package session
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	the path gate keeps transactions touching the same path in submission order
+	each queued transaction holds a ticket until it leaves the backend (processed, cancelled or archived)
+	a transaction is only ready for dispatch if no older ticket touches the same path,
+	a recursive ticket on one of its ancestors or, for recursive transactions, a path under it
+	the gate works on virtual paths, so it covers local and remote filesystem servers alike
+
+	queued transactions with a ready ticket are kept in ready lists per session and lane, oldest first
+	a ticket never turns unready, and only the release of an older ticket makes it ready,
+	so dispatch looks at the heads of the ready lists instead of every queued transaction
+*/
+
+/* path touched by a transaction */
+type gatePath struct {
+	path      string
+	recursive bool
+}
+
+/* position of a ticket in one of the gate indexes */
+type gateElem struct {
+	index map[string]*list.List
+	key   string
+	elem  *list.Element
+}
+
+/* admission of a transaction into the gate */
+type gateTicket struct {
+	seq   uint64
+	paths []gatePath
+	elems []gateElem
+
+	/* no older ticket conflicts with this one */
+	ready bool
+
+	/* set while the transaction waits in a session queue */
+	txn       *types.Transaction
+	sessionID uuid.UUID
+	queueElem *list.Element
+
+	/* position in the ready list of its session, nil unless queued and ready */
+	lane      int
+	readyElem *list.Element
+}
+
+/* tickets are indexed by path, every index list is ordered by submission */
+type pathGate struct {
+	mutex   sync.Mutex
+	nextSeq uint64
+	tickets map[uuid.UUID]*gateTicket
+
+	/* tickets by the paths they touch */
+	exact map[string]*list.List
+
+	/* recursive tickets by their root path */
+	recursive map[string]*list.List
+
+	/* tickets by every ancestor of the paths they touch */
+	under map[string]*list.List
+
+	/* ready lists of queued transactions by session, one per priority lane */
+	queues map[uuid.UUID][]*list.List
+}
+
+/* create a new path gate */
+func newPathGate() *pathGate {
+	return &pathGate{
+		tickets:   make(map[uuid.UUID]*gateTicket),
+		exact:     make(map[string]*list.List),
+		recursive: make(map[string]*list.List),
+		under:     make(map[string]*list.List),
+		queues:    make(map[uuid.UUID][]*list.List),
+	}
+}
+
+/* issues a ticket for a transaction, a requeued transaction keeps the ticket it has */
+func (g *pathGate) admit(txn *types.Transaction) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if _, ok := g.tickets[txn.ID]; ok {
+		return
+	}
+
+	g.nextSeq++
+	ticket := &gateTicket{
+		seq:   g.nextSeq,
+		paths: transactionPaths(txn),
+	}
+
+	for _, p := range ticket.paths {
+		ticket.push(g.exact, p.path)
+		if p.recursive {
+			ticket.push(g.recursive, p.path)
+		}
+		for _, ancestor := range ancestors(p.path) {
+			ticket.push(g.under, ancestor)
+		}
+	}
+
+	ticket.ready = g.readyLocked(ticket)
+	g.tickets[txn.ID] = ticket
+}
+
+/*
+marks an admitted transaction as waiting in the queue of a session at queueElem
+it is put in the ready list of its lane as soon as its ticket is ready - assumes caller holds the session lock
+*/
+func (g *pathGate) enqueue(sessionID uuid.UUID, txn *types.Transaction, queueElem *list.Element) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ticket, ok := g.tickets[txn.ID]
+	if !ok {
+		return
+	}
+
+	ticket.txn = txn
+	ticket.sessionID = sessionID
+	ticket.queueElem = queueElem
+
+	if ticket.ready {
+		g.pushReady(ticket)
+	}
+}
+
+/* reports if no older ticket conflicts with the ticket of a transaction */
+func (g *pathGate) ready(txnID uuid.UUID) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ticket, ok := g.tickets[txnID]
+	if !ok {
+		return true
+	}
+
+	return ticket.ready
+}
+
+/*
+highest lane among queued transactions that are ready and admitted by their filesystem servers, false if none is
+older transactions have aged more, so a ready list is only walked past transactions parked on their servers
+*/
+func (g *pathGate) readyLane(now time.Time, servers *serverGate) (int, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	highest, found := 0, false
+	for _, lanes := range g.queues {
+		for _, l := range lanes {
+			for e := l.Front(); e != nil; e = e.Next() {
+				ticket := e.Value.(*gateTicket)
+
+				lane := effectiveLane(ticket.txn, now)
+				if found && lane <= highest {
+					break
+				}
+				if servers.admits(ticket.txn) {
+					highest, found = lane, true
+					break
+				}
+			}
+		}
+	}
+
+	return highest, found
+}
+
+/*
+takes the oldest ready transaction of a session dispatched in at least the given lane and a slot on its filesystem servers
+returns the transaction and its element in the session queue, nil if none is ready - assumes caller holds the session lock
+*/
+func (g *pathGate) popReady(sessionID uuid.UUID, lane int, now time.Time, servers *serverGate) (*types.Transaction, *list.Element) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	var best *gateTicket
+	for _, l := range g.queues[sessionID] {
+		for e := l.Front(); e != nil; e = e.Next() {
+			ticket := e.Value.(*gateTicket)
+
+			/* younger transactions of the lane have aged less */
+			if effectiveLane(ticket.txn, now) < lane {
+				break
+			}
+			if best != nil && !ticket.txn.Timestamp.Before(best.txn.Timestamp) {
+				break
+			}
+			if servers.admits(ticket.txn) {
+				best = ticket
+				break
+			}
+		}
+	}
+
+	if best == nil || !servers.acquire(best.txn) {
+		return nil, nil
+	}
+
+	txn, queueElem := best.txn, best.queueElem
+	g.dequeueLocked(best)
+
+	return txn, queueElem
+}
+
+/* reports if no older ticket conflicts with a ticket - assumes caller holds the gate lock */
+func (g *pathGate) readyLocked(ticket *gateTicket) bool {
+	for _, p := range ticket.paths {
+		if g.older(g.exact, p.path, ticket.seq) {
+			return false
+		}
+		for _, ancestor := range ancestors(p.path) {
+			if g.older(g.recursive, ancestor, ticket.seq) {
+				return false
+			}
+		}
+		if p.recursive && g.older(g.under, p.path, ticket.seq) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/* removes the ticket of a transaction, transactions waiting on it may be dispatched */
+func (g *pathGate) release(txnID uuid.UUID) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ticket, ok := g.tickets[txnID]
+	if !ok {
+		return
+	}
+
+	waiters := g.waiters(ticket)
+	g.dequeueLocked(ticket)
+
+	for _, e := range ticket.elems {
+		l := e.index[e.key]
+		l.Remove(e.elem)
+		if l.Len() == 0 {
+			delete(e.index, e.key)
+		}
+	}
+
+	delete(g.tickets, txnID)
+
+	for _, waiter := range waiters {
+		if waiter == ticket || waiter.ready || !g.readyLocked(waiter) {
+			continue
+		}
+		waiter.ready = true
+		if waiter.queueElem != nil {
+			g.pushReady(waiter)
+		}
+	}
+}
+
+/*
+tickets that may wait on a ticket, the mirror image of the checks in readyLocked
+same paths, paths under its recursive roots and recursive roots above its paths - assumes caller holds the gate lock
+*/
+func (g *pathGate) waiters(ticket *gateTicket) []*gateTicket {
+	var result []*gateTicket
+
+	collect := func(index map[string]*list.List, key string) {
+		l, ok := index[key]
+		if !ok {
+			return
+		}
+		for e := l.Front(); e != nil; e = e.Next() {
+			result = append(result, e.Value.(*gateTicket))
+		}
+	}
+
+	for _, p := range ticket.paths {
+		collect(g.exact, p.path)
+		if p.recursive {
+			collect(g.under, p.path)
+		}
+		for _, ancestor := range ancestors(p.path) {
+			collect(g.recursive, ancestor)
+		}
+	}
+
+	return result
+}
+
+/* adds a queued ticket to the ready list of its lane, ordered by submission time - assumes caller holds the gate lock */
+func (g *pathGate) pushReady(ticket *gateTicket) {
+	lanes, ok := g.queues[ticket.sessionID]
+	if !ok {
+		lanes = make([]*list.List, len(priorityLanes))
+		for i := range lanes {
+			lanes[i] = list.New()
+		}
+		g.queues[ticket.sessionID] = lanes
+	}
+
+	ticket.lane = laneOf(ticket.txn.Priority)
+	l := lanes[ticket.lane]
+
+	/* transactions mostly arrive in order, requeued and replayed ones are older */
+	for e := l.Back(); e != nil; e = e.Prev() {
+		if !ticket.txn.Timestamp.Before(e.Value.(*gateTicket).txn.Timestamp) {
+			ticket.readyElem = l.InsertAfter(ticket, e)
+			return
+		}
+	}
+	ticket.readyElem = l.PushFront(ticket)
+}
+
+/* the transaction of a ticket left its session queue - assumes caller holds the gate lock */
+func (g *pathGate) dequeueLocked(ticket *gateTicket) {
+	if ticket.readyElem != nil {
+		lanes := g.queues[ticket.sessionID]
+		lanes[ticket.lane].Remove(ticket.readyElem)
+		ticket.readyElem = nil
+
+		empty := true
+		for _, l := range lanes {
+			if l.Len() != 0 {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			delete(g.queues, ticket.sessionID)
+		}
+	}
+
+	ticket.txn = nil
+	ticket.queueElem = nil
+}
+
+/* reports if the oldest ticket in an index list was issued before seq - assumes caller holds the gate lock */
+func (g *pathGate) older(index map[string]*list.List, key string, seq uint64) bool {
+	l, ok := index[key]
+	if !ok {
+		return false
+	}
+	return l.Front().Value.(*gateTicket).seq < seq
+}
+
+/* appends a ticket to an index list, tickets are issued in order so lists stay sorted */
+func (t *gateTicket) push(index map[string]*list.List, key string) {
+	l, ok := index[key]
+	if !ok {
+		l = list.New()
+		index[key] = l
+	}
+	t.elems = append(t.elems, gateElem{index: index, key: key, elem: l.PushBack(t)})
+}
+
+/* paths read or written by a transaction */
+func transactionPaths(txn *types.Transaction) []gatePath {
+	var paths []gatePath
+
+	add := func(p string, recursive bool) {
+		if p == "" {
+			return
+		}
+		paths = append(paths, gatePath{path: path.Clean(p), recursive: recursive})
+	}
+
+	if txn.Operation == types.OperationChangeset {
+		for _, step := range txn.Steps {
+			add(step.TargetPath, false)
+		}
+		return paths
+	}
+
+	add(txn.TargetPath, txn.Recursive)
+
+	/* a copy must not read the source before older changes to it are applied */
+	add(txn.SourcePath, false)
+
+	return paths
+}
+
+/* ancestors of a clean path, closest first */
+func ancestors(p string) []string {
+	var result []string
+	for dir := path.Dir(p); dir != p; p, dir = dir, path.Dir(dir) {
+		result = append(result, dir)
+	}
+	return result
+}
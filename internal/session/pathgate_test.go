@@ -0,0 +1,315 @@
+package session
+
+import (
+	"container/list"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	no filesystem servers are configured in the tests, so the server gate admits every transaction
+	transactions are identified by their index in a test case, tickets are issued in that order
+*/
+
+/* setfacl transaction on a path, options turn it into other kinds */
+func gateTxn(target string, opts ...func(*types.Transaction)) *types.Transaction {
+	txn := &types.Transaction{
+		ID:         uuid.New(),
+		Timestamp:  time.Now(),
+		Operation:  types.OperationSetACL,
+		TargetPath: target,
+	}
+	for _, opt := range opts {
+		opt(txn)
+	}
+	return txn
+}
+
+func recursive(txn *types.Transaction) { txn.Recursive = true }
+
+func copyFrom(source string) func(*types.Transaction) {
+	return func(txn *types.Transaction) {
+		txn.Operation = types.OperationCopyACL
+		txn.SourcePath = source
+	}
+}
+
+func changeset(paths ...string) func(*types.Transaction) {
+	return func(txn *types.Transaction) {
+		txn.Operation = types.OperationChangeset
+		for _, p := range paths {
+			txn.Steps = append(txn.Steps, types.ChangesetStep{TargetPath: p})
+		}
+	}
+}
+
+/* admits and queues transactions into one session, returns the session queue */
+func queueAll(g *pathGate, sessionID uuid.UUID, txns []*types.Transaction) *list.List {
+	queue := list.New()
+	for _, txn := range txns {
+		g.admit(txn)
+		g.enqueue(sessionID, txn, queue.PushBack(txn))
+	}
+	return queue
+}
+
+/* indexes of the transactions with a ready ticket */
+func readyIndexes(g *pathGate, txns []*types.Transaction) []int {
+	var result []int
+	for i, txn := range txns {
+		if g.ready(txn.ID) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+/* pops every ready transaction of a session, returns their indexes in dispatch order */
+func popAll(g *pathGate, sessionID uuid.UUID, servers *serverGate, txns []*types.Transaction) []int {
+	var result []int
+	for {
+		txn, _ := g.popReady(sessionID, 0, time.Now(), servers)
+		if txn == nil {
+			return result
+		}
+		for i := range txns {
+			if txns[i] == txn {
+				result = append(result, i)
+			}
+		}
+	}
+}
+
+func equalIndexes(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPathGateConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		txns      []*types.Transaction
+		wantReady []int
+	}{
+		{
+			name:      "same path waits",
+			txns:      []*types.Transaction{gateTxn("/srv/a"), gateTxn("/srv/a")},
+			wantReady: []int{0},
+		},
+		{
+			name:      "unrelated paths",
+			txns:      []*types.Transaction{gateTxn("/srv/a"), gateTxn("/srv/b"), gateTxn("/srv/ab")},
+			wantReady: []int{0, 1, 2},
+		},
+		{
+			name:      "descendant waits on recursive ancestor",
+			txns:      []*types.Transaction{gateTxn("/srv/a", recursive), gateTxn("/srv/a/b/c")},
+			wantReady: []int{0},
+		},
+		{
+			name:      "recursive ancestor waits on descendant",
+			txns:      []*types.Transaction{gateTxn("/srv/a/b/c"), gateTxn("/srv/a", recursive)},
+			wantReady: []int{0},
+		},
+		{
+			name:      "descendant doesn't wait on non-recursive ancestor",
+			txns:      []*types.Transaction{gateTxn("/srv/a"), gateTxn("/srv/a/b")},
+			wantReady: []int{0, 1},
+		},
+		{
+			name:      "recursive siblings",
+			txns:      []*types.Transaction{gateTxn("/srv/a", recursive), gateTxn("/srv/b", recursive), gateTxn("/srv/ab/c")},
+			wantReady: []int{0, 1, 2},
+		},
+		{
+			name:      "nested recursive transactions",
+			txns:      []*types.Transaction{gateTxn("/srv/a/b", recursive), gateTxn("/srv/a", recursive)},
+			wantReady: []int{0},
+		},
+		{
+			name:      "paths are cleaned",
+			txns:      []*types.Transaction{gateTxn("/srv/a/", recursive), gateTxn("/srv/x/../a/b")},
+			wantReady: []int{0},
+		},
+		{
+			name:      "copy waits on its source",
+			txns:      []*types.Transaction{gateTxn("/srv/src"), gateTxn("/srv/dst", copyFrom("/srv/src"))},
+			wantReady: []int{0},
+		},
+		{
+			name:      "changeset waits on any of its steps",
+			txns:      []*types.Transaction{gateTxn("/srv/b"), gateTxn("/srv/a", changeset("/srv/a", "/srv/b")), gateTxn("/srv/a")},
+			wantReady: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newPathGate()
+			queueAll(g, uuid.New(), tt.txns)
+
+			if got := readyIndexes(g, tt.txns); !equalIndexes(got, tt.wantReady) {
+				t.Errorf("ready = %v, want %v", got, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestPathGateWaiterOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		txns []*types.Transaction
+
+		/* transactions released one after the other, each followed by popping every ready transaction */
+		release []int
+		want    [][]int
+	}{
+		{
+			name:    "same path in submission order",
+			txns:    []*types.Transaction{gateTxn("/srv/a"), gateTxn("/srv/a"), gateTxn("/srv/a")},
+			release: []int{0, 1, 2},
+			want:    [][]int{{1}, {2}, nil},
+		},
+		{
+			name:    "recursive ancestor wakes every descendant",
+			txns:    []*types.Transaction{gateTxn("/srv/a", recursive), gateTxn("/srv/a/x"), gateTxn("/srv/a/y"), gateTxn("/srv/a/x")},
+			release: []int{0, 1, 2, 3},
+			want:    [][]int{{1, 2}, {3}, nil, nil},
+		},
+		{
+			name:    "recursive transaction waits for every descendant",
+			txns:    []*types.Transaction{gateTxn("/srv/a/x"), gateTxn("/srv/a/y"), gateTxn("/srv/a", recursive), gateTxn("/srv/a/x")},
+			release: []int{0, 1, 2},
+			want:    [][]int{nil, {2}, {3}},
+		},
+		{
+			name:    "younger transaction keeps waiting behind an older waiter",
+			txns:    []*types.Transaction{gateTxn("/srv/a", recursive), gateTxn("/srv/a/x"), gateTxn("/srv/a", recursive)},
+			release: []int{0, 1},
+			want:    [][]int{{1}, {2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newPathGate()
+			servers := newServerGate(nil)
+			sessionID := uuid.New()
+			queueAll(g, sessionID, tt.txns)
+
+			/* transactions ready from the start are taken first */
+			popAll(g, sessionID, servers, tt.txns)
+
+			for i, idx := range tt.release {
+				g.release(tt.txns[idx].ID)
+				if got := popAll(g, sessionID, servers, tt.txns); !equalIndexes(got, tt.want[i]) {
+					t.Errorf("after release of %d: dispatched %v, want %v", idx, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPathGateReadyOrder(t *testing.T) {
+	now := time.Now()
+	at := func(offset time.Duration) func(*types.Transaction) {
+		return func(txn *types.Transaction) { txn.Timestamp = now.Add(offset) }
+	}
+
+	/* a requeued transaction is older than the ones queued after it */
+	txns := []*types.Transaction{
+		gateTxn("/srv/a", at(0)),
+		gateTxn("/srv/b", at(time.Second)),
+		gateTxn("/srv/c", at(-time.Second)),
+		gateTxn("/srv/d", at(2*time.Second)),
+	}
+
+	g := newPathGate()
+	sessionID := uuid.New()
+	queueAll(g, sessionID, txns)
+
+	want := []int{2, 0, 1, 3}
+	if got := popAll(g, sessionID, newServerGate(nil), txns); !equalIndexes(got, want) {
+		t.Errorf("dispatched %v, want %v", got, want)
+	}
+}
+
+func TestPathGateReleaseQueued(t *testing.T) {
+	tests := []struct {
+		name string
+
+		/* requeue puts the first transaction back into the queue after it was dispatched */
+		requeue bool
+
+		/* releases the first transaction while it waits in the queue, like a cancellation */
+		cancel bool
+
+		wantDispatched []int
+	}{
+		{
+			name:           "cancelled transaction leaves the ready list and wakes its waiter",
+			cancel:         true,
+			wantDispatched: []int{1, 2},
+		},
+		{
+			name:           "requeued transaction keeps its ticket",
+			requeue:        true,
+			wantDispatched: []int{0, 2},
+		},
+		{
+			name:           "requeued transaction released on cancel",
+			requeue:        true,
+			cancel:         true,
+			wantDispatched: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns := []*types.Transaction{gateTxn("/srv/a", recursive), gateTxn("/srv/a/x"), gateTxn("/srv/b")}
+
+			g := newPathGate()
+			servers := newServerGate(nil)
+			sessionID := uuid.New()
+			queue := queueAll(g, sessionID, txns)
+
+			if tt.requeue {
+				txn, elem := g.popReady(sessionID, 0, time.Now(), servers)
+				if txn != txns[0] {
+					t.Fatalf("first dispatch is not the oldest transaction")
+				}
+				queue.Remove(elem)
+				servers.release(txn)
+
+				/* admitted again on its way back, the ticket it holds stays */
+				g.admit(txn)
+				g.enqueue(sessionID, txn, queue.PushBack(txn))
+			}
+
+			if tt.cancel {
+				g.release(txns[0].ID)
+			}
+
+			got := popAll(g, sessionID, servers, txns)
+			if !equalIndexes(got, tt.wantDispatched) {
+				t.Errorf("dispatched %v, want %v", got, tt.wantDispatched)
+			}
+
+			/* transactions still waiting aren't ready, so every ready list is empty */
+			if len(g.queues) != 0 {
+				t.Errorf("ready lists left for %d sessions", len(g.queues))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	schedulers always dispatch from the highest lane with a ready transaction
+	a waiting transaction moves up one lane every priority.aging_interval,
+	so low priority work eventually runs even while higher lanes are busy
+*/
+
+/* priority lanes in dispatch order, the index is the lane */
+var priorityLanes = []types.Priority{
+	types.PriorityLow,
+	types.PriorityNormal,
+	types.PriorityHigh,
+	types.PriorityUrgent,
+}
+
+/* lane of a priority, unknown and empty priorities are normal */
+func laneOf(priority types.Priority) int {
+	for lane, p := range priorityLanes {
+		if p == priority {
+			return lane
+		}
+	}
+	return laneOf(types.PriorityNormal)
+}
+
+/* priority stored for a transaction, normal if empty */
+func normalizePriority(priority types.Priority) types.Priority {
+	return priorityLanes[laneOf(priority)]
+}
+
+/* lane of a transaction after aging since it was queued */
+func effectiveLane(txn *types.Transaction, now time.Time) int {
+	lane := laneOf(txn.Priority)
+
+	aging := time.Duration(config.BackendConfig.Priority.AgingInterval) * time.Second
+	if aging > 0 {
+		lane += int(now.Sub(txn.Timestamp) / aging)
+	}
+
+	return min(lane, len(priorityLanes)-1)
+}
+
+/* highest lane among transactions ready for dispatch in any session, false if none is ready */
+func (m *Manager) ReadyLane() (int, bool) {
+	return m.gate.readyLane(time.Now(), m.servers)
+}
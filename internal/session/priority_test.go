@@ -0,0 +1,240 @@
+package session
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* sets the aging interval for the duration of a test */
+func withAging(t *testing.T, seconds int) {
+	previous := config.BackendConfig.Priority.AgingInterval
+	config.BackendConfig.Priority.AgingInterval = seconds
+	t.Cleanup(func() { config.BackendConfig.Priority.AgingInterval = previous })
+}
+
+func withPriority(priority types.Priority) func(*types.Transaction) {
+	return func(txn *types.Transaction) { txn.Priority = priority }
+}
+
+func queuedAt(at time.Time) func(*types.Transaction) {
+	return func(txn *types.Transaction) { txn.Timestamp = at }
+}
+
+func TestNormalizePriority(t *testing.T) {
+	tests := []struct {
+		priority types.Priority
+		want     types.Priority
+	}{
+		{priority: "", want: types.PriorityNormal},
+		{priority: "bogus", want: types.PriorityNormal},
+		{priority: "URGENT", want: types.PriorityNormal},
+		{priority: types.PriorityLow, want: types.PriorityLow},
+		{priority: types.PriorityNormal, want: types.PriorityNormal},
+		{priority: types.PriorityHigh, want: types.PriorityHigh},
+		{priority: types.PriorityUrgent, want: types.PriorityUrgent},
+	}
+
+	for _, tt := range tests {
+		if got := normalizePriority(tt.priority); got != tt.want {
+			t.Errorf("normalizePriority(%q) = %q, want %q", tt.priority, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveLane(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		aging    int
+		priority types.Priority
+		waited   time.Duration
+		want     types.Priority
+	}{
+		{name: "fresh transaction keeps its lane", aging: 300, priority: types.PriorityLow, waited: 0, want: types.PriorityLow},
+		{name: "just below one interval", aging: 300, priority: types.PriorityLow, waited: 299 * time.Second, want: types.PriorityLow},
+		{name: "one interval moves up a lane", aging: 300, priority: types.PriorityLow, waited: 300 * time.Second, want: types.PriorityNormal},
+		{name: "two intervals move up two lanes", aging: 300, priority: types.PriorityLow, waited: 610 * time.Second, want: types.PriorityHigh},
+		{name: "aging stops at urgent", aging: 300, priority: types.PriorityLow, waited: time.Hour, want: types.PriorityUrgent},
+		{name: "urgent stays urgent", aging: 300, priority: types.PriorityUrgent, waited: time.Hour, want: types.PriorityUrgent},
+		{name: "unknown priority ages from normal", aging: 300, priority: "bogus", waited: 300 * time.Second, want: types.PriorityHigh},
+		{name: "no aging without an interval", aging: 0, priority: types.PriorityLow, waited: time.Hour, want: types.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withAging(t, tt.aging)
+
+			txn := gateTxn("/srv/a", withPriority(tt.priority), queuedAt(now.Add(-tt.waited)))
+			if got := effectiveLane(txn, now); got != laneOf(tt.want) {
+				t.Errorf("lane = %s, want %s", priorityLanes[got], tt.want)
+			}
+		})
+	}
+}
+
+func TestLaneSelection(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		aging int
+
+		/* transactions of each session */
+		sessions [][]*types.Transaction
+
+		/* lane picked first, and the session and transaction dispatched from it */
+		wantLane types.Priority
+		wantTxn  [2]int
+	}{
+		{
+			name:  "highest lane across sessions",
+			aging: 300,
+			sessions: [][]*types.Transaction{
+				{gateTxn("/srv/a", withPriority(types.PriorityNormal), queuedAt(now.Add(-time.Minute)))},
+				{gateTxn("/srv/b", withPriority(types.PriorityHigh), queuedAt(now))},
+			},
+			wantLane: types.PriorityHigh,
+			wantTxn:  [2]int{1, 0},
+		},
+		{
+			name:  "older transaction within a session's lane",
+			aging: 300,
+			sessions: [][]*types.Transaction{
+				{
+					gateTxn("/srv/a", withPriority(types.PriorityLow), queuedAt(now.Add(-2*time.Second))),
+					gateTxn("/srv/b", withPriority(types.PriorityUrgent), queuedAt(now.Add(-time.Second))),
+					gateTxn("/srv/c", withPriority(types.PriorityUrgent), queuedAt(now.Add(-3*time.Second))),
+				},
+			},
+			wantLane: types.PriorityUrgent,
+			wantTxn:  [2]int{0, 2},
+		},
+		{
+			name:  "starved low transaction aged past a busy normal lane",
+			aging: 300,
+			sessions: [][]*types.Transaction{
+				{gateTxn("/srv/a", withPriority(types.PriorityLow), queuedAt(now.Add(-11*time.Minute)))},
+				{
+					gateTxn("/srv/b", withPriority(types.PriorityNormal), queuedAt(now)),
+					gateTxn("/srv/c", withPriority(types.PriorityNormal), queuedAt(now)),
+				},
+			},
+			wantLane: types.PriorityHigh,
+			wantTxn:  [2]int{0, 0},
+		},
+		{
+			name:  "starved low transaction waits without aging",
+			aging: 0,
+			sessions: [][]*types.Transaction{
+				{gateTxn("/srv/a", withPriority(types.PriorityLow), queuedAt(now.Add(-time.Hour)))},
+				{gateTxn("/srv/b", withPriority(types.PriorityNormal), queuedAt(now))},
+			},
+			wantLane: types.PriorityNormal,
+			wantTxn:  [2]int{1, 0},
+		},
+		{
+			name:  "transaction blocked on its path doesn't pick the lane",
+			aging: 300,
+			sessions: [][]*types.Transaction{
+				{
+					gateTxn("/srv/a", withPriority(types.PriorityLow), queuedAt(now.Add(-time.Second))),
+					gateTxn("/srv/a", withPriority(types.PriorityUrgent), queuedAt(now)),
+				},
+			},
+			wantLane: types.PriorityLow,
+			wantTxn:  [2]int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withAging(t, tt.aging)
+
+			g := newPathGate()
+			servers := newServerGate(noCircuits{})
+			sessionIDs := make([]uuid.UUID, len(tt.sessions))
+			for i, txns := range tt.sessions {
+				sessionIDs[i] = uuid.New()
+				queueAll(g, sessionIDs[i], txns)
+			}
+
+			lane, ok := g.readyLane(now, servers)
+			if !ok {
+				t.Fatalf("no ready lane")
+			}
+			if lane != laneOf(tt.wantLane) {
+				t.Errorf("lane = %s, want %s", priorityLanes[lane], tt.wantLane)
+			}
+
+			/* sessions without a transaction in the lane have nothing to dispatch */
+			for i, sessionID := range sessionIDs {
+				txn, _ := g.popReady(sessionID, lane, now, servers)
+				if i != tt.wantTxn[0] {
+					if txn != nil {
+						t.Errorf("session %d dispatched %s in lane %s", i, txn.TargetPath, priorityLanes[lane])
+					}
+					continue
+				}
+				if want := tt.sessions[i][tt.wantTxn[1]]; txn != want {
+					t.Errorf("session %d dispatched %v, want %s", i, txn, want.TargetPath)
+				}
+			}
+		})
+	}
+}
+
+func TestAddTransactionPersistsNormalPriority(t *testing.T) {
+	tests := []struct {
+		priority types.Priority
+		want     types.Priority
+	}{
+		{priority: "", want: types.PriorityNormal},
+		{priority: "bogus", want: types.PriorityNormal},
+		{priority: types.PriorityLow, want: types.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.priority), func(t *testing.T) {
+			m, _, db, errCh := newFakeManager()
+			session := &Session{ID: uuid.New(), Username: "alice", TransactionQueue: list.New()}
+
+			txn := gateTxn("/srv/a", withPriority(tt.priority))
+			if err := m.AddTransaction(session, txn); err != nil {
+				t.Fatalf("AddTransaction: %v", err)
+			}
+			if len(errCh) != 0 {
+				t.Fatalf("AddTransaction reported %v", <-errCh)
+			}
+
+			/* the priority is normalized in the durable queue, not only in memory */
+			var persisted *types.Transaction
+			for _, stmt := range db.statements() {
+				if !strings.Contains(stmt.sql, "INSERT INTO queued_transactions") {
+					continue
+				}
+				persisted = &types.Transaction{}
+				if err := json.Unmarshal(stmt.args[3].([]byte), persisted); err != nil {
+					t.Fatalf("queued transaction isn't JSON: %v", err)
+				}
+			}
+			if persisted == nil {
+				t.Fatalf("transaction wasn't persisted")
+			}
+			if persisted.Priority != tt.want {
+				t.Errorf("persisted priority = %q, want %q", persisted.Priority, tt.want)
+			}
+			if txn.Priority != tt.want {
+				t.Errorf("queued priority = %q, want %q", txn.Priority, tt.want)
+			}
+		})
+	}
+}
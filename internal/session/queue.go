@@ -0,0 +1,171 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/postgresql"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	every queued transaction is also written to queued_transactions in PostgreSQL
+	session queues only decide the order of execution, the table is what survives a crash
+	a transaction is acknowledged (deleted from the table) once its result is archived
+	or once it was moved to the pending archive
+	the instance that queued a transaction holds a lease on it as long as it heartbeats in backend_instances,
+	transactions of an instance whose lease expired (a crash or a kill) are reclaimed by the instances still running
+*/
+
+/* max queued transactions reclaimed from PostgreSQL at once */
+const reclaimBatch = 100
+
+/* writes a transaction to the durable queue, a requeued transaction is updated in place */
+func (m *Manager) persistQueuedTransaction(username string, txn *types.Transaction) error {
+	txnJSON, err := json.Marshal(txn)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queued transaction: %w", err)
+	}
+
+	if err := m.archivalPQ.EnqueueTransactionPQ(context.Background(), postgresql.EnqueueTransactionPQParams{
+		ID:          txn.ID,
+		Username:    username,
+		Owner:       m.instanceID,
+		Transaction: txnJSON,
+	}); err != nil {
+		return fmt.Errorf("failed to store queued transaction: %w", err)
+	}
+
+	return nil
+}
+
+/* marks a transaction taken off a session queue as being processed */
+func (m *Manager) ClaimTransaction(txn *types.Transaction) {
+	if err := m.archivalPQ.ClaimQueuedTransactionPQ(context.Background(), txn.ID); err != nil {
+		m.errCh <- fmt.Errorf("failed to claim queued transaction %s: %w", txn.ID, err)
+	}
+}
+
+/* removes a transaction from the durable queue */
+func (m *Manager) ackQueuedTransaction(txn *types.Transaction) {
+	if err := m.archivalPQ.AckQueuedTransactionPQ(context.Background(), txn.ID); err != nil {
+		m.errCh <- fmt.Errorf("failed to acknowledge queued transaction %s: %w", txn.ID, err)
+	}
+}
+
+/*
+stores the result of a transaction in the results archive and acknowledges it in the durable queue
+a replayed transaction is removed from the pending archive at the same time
+*/
+func (m *Manager) storeTransactionResult(txn *types.Transaction) error {
+	ctx := context.Background()
+
+	params, err := ConvertTransactionResulttoStoreParams(*txn)
+	if err != nil {
+		return fmt.Errorf("failed to convert transaction result to archive format: %w", err)
+	}
+	if _, err := m.archivalPQ.CreateResultsTransactionPQ(ctx, params); err != nil {
+		return fmt.Errorf("failed to archive transaction result %s: %w", txn.ID, err)
+	}
+	txn.Archived = true
+
+	if txn.Replayed {
+		if err := m.archivalPQ.DeletePendingTransactionPQ(ctx, txn.ID); err != nil {
+			m.errCh <- fmt.Errorf("failed to remove replayed transaction %s from pending archive: %w", txn.ID, err)
+		}
+	}
+
+	/* the result is safe, the queue entry isn't needed anymore */
+	m.ackQueuedTransaction(txn)
+
+	return nil
+}
+
+/* renews the lease this instance holds on its queued transactions */
+func (m *Manager) Heartbeat(ctx context.Context) error {
+	if err := m.archivalPQ.HeartbeatInstancePQ(ctx, m.instanceID); err != nil {
+		return fmt.Errorf("failed to renew queue lease: %w", err)
+	}
+	return nil
+}
+
+/* gives up the lease on shutdown, transactions still queued can be reclaimed right away */
+func (m *Manager) ReleaseInstance(ctx context.Context) error {
+	if err := m.archivalPQ.ReleaseInstancePQ(ctx, m.instanceID); err != nil {
+		return fmt.Errorf("failed to release queue lease: %w", err)
+	}
+	return nil
+}
+
+/*
+heartbeats every interval and reclaims transactions of instances that didn't heartbeat within the lease
+instances left without transactions after their lease expired are removed
+*/
+func (m *Manager) RunQueueLease(ctx context.Context, interval, lease time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Heartbeat(ctx); err != nil {
+				m.errCh <- err
+			}
+
+			if err := m.RecoverQueuedTransactions(ctx, lease); err != nil {
+				m.errCh <- err
+				continue
+			}
+
+			expired := pgtype.Timestamptz{Time: time.Now().Add(-lease), Valid: true}
+			if err := m.archivalPQ.PruneInstancesPQ(ctx, expired); err != nil {
+				m.errCh <- fmt.Errorf("failed to prune expired backend instances: %w", err)
+			}
+		}
+	}
+}
+
+/*
+queues transactions left unacknowledged by an instance whose lease expired (a crash or a kill)
+transactions that were being processed are executed again, a session is created for users who aren't logged in
+*/
+func (m *Manager) RecoverQueuedTransactions(ctx context.Context, lease time.Duration) error {
+	expired := pgtype.Timestamptz{Time: time.Now().Add(-lease), Valid: true}
+
+	for {
+		rows, err := m.archivalPQ.ReclaimQueuedTransactionsPQ(ctx, postgresql.ReclaimQueuedTransactionsPQParams{
+			Owner:       m.instanceID,
+			Limit:       reclaimBatch,
+			HeartbeatAt: expired,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reclaim queued transactions: %w", err)
+		}
+
+		for _, row := range rows {
+			var tx types.Transaction
+			if err := json.Unmarshal(row.Transaction, &tx); err != nil {
+				m.errCh <- fmt.Errorf("failed to unmarshal queued transaction %s: %w", row.ID, err)
+				continue
+			}
+
+			/* duration is measured from the moment the transaction is queued again */
+			tx.Status = types.StatusPending
+			tx.Timestamp = time.Now()
+
+			if err := m.ScheduleBackgroundTransaction(row.Username, &tx); err != nil {
+				m.errCh <- fmt.Errorf("failed to recover queued transaction %s: %w", row.ID, err)
+			}
+		}
+
+		if len(rows) < reclaimBatch {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	transactions still queued when a session expires (or the backend shuts down) are written
+	to the pending archive by ExpireSession, depending on replay.mode they are either queued
+	again into the next session of the user or offered back to the user
+*/
+
+/*
+lists transactions of a user waiting in the pending archive
+transactions that were replayed into the active session already are left out
+*/
+func (m *Manager) ListRecoverableTransactions(username string) ([]types.Transaction, error) {
+	rows, err := m.archivalPQ.GetPendingTransactionsByUserPQ(context.Background(), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transactions from archive: %w", err)
+	}
+
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	transactions := make([]types.Transaction, 0, len(rows))
+	for _, row := range rows {
+		if session != nil {
+			queued, err := m.isPendingInSession(session, row.ID)
+			if err != nil {
+				return nil, err
+			}
+			if queued {
+				continue
+			}
+		}
+
+		tx, err := ConvertPendingArchiveToTransaction(row)
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to convert archived pending transaction %s: %w", row.ID, err)
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+/* queues a transaction from the pending archive into the active session of the user */
+func (m *Manager) ReplayPendingTransaction(username string, txnID uuid.UUID) (*types.Transaction, error) {
+	if config.BackendConfig.Replay.Mode == config.ReplayOff {
+		return nil, ErrReplayDisabled
+	}
+
+	row, err := m.archivalPQ.GetPendingTransactionPQ(context.Background(), txnID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction from pending archive: %w", err)
+	}
+
+	/* transactions of other users are treated as if they don't exist */
+	if row.ExecutedBy != username {
+		return nil, ErrTransactionNotFound
+	}
+
+	tx, err := ConvertPendingArchiveToTransaction(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.enqueueReplayedTransaction(username, &tx); err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+/* queues every transaction of a user from the pending archive into the active session */
+func (m *Manager) replayPendingTransactions(username string) {
+	rows, err := m.archivalPQ.GetPendingTransactionsByUserPQ(context.Background(), username)
+	if err != nil {
+		m.errCh <- fmt.Errorf("failed to get pending transactions of %s from archive: %w", username, err)
+		return
+	}
+
+	for _, row := range rows {
+		tx, err := ConvertPendingArchiveToTransaction(row)
+		if err != nil {
+			m.errCh <- fmt.Errorf("failed to convert archived pending transaction %s: %w", row.ID, err)
+			continue
+		}
+
+		if err := m.enqueueReplayedTransaction(username, &tx); err != nil && !errors.Is(err, ErrTransactionQueued) {
+			m.errCh <- fmt.Errorf("failed to replay pending transaction %s: %w", row.ID, err)
+		}
+	}
+}
+
+/*
+replays pending transactions of every user in the archive on startup
+a background session is created for each user, replaying them like a login would
+*/
+func (m *Manager) ReplayPendingTransactionsOnStartup() error {
+	if config.BackendConfig.Replay.Mode != config.ReplayEnqueue || !config.BackendConfig.Replay.OnStartup {
+		return nil
+	}
+
+	usernames, err := m.archivalPQ.GetPendingTransactionUsersPQ(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get users with pending transactions: %w", err)
+	}
+
+	for _, username := range usernames {
+		if _, err := m.CreateSession(username, "", backgroundUserAgent); err != nil {
+			m.errCh <- fmt.Errorf("failed to create background session for %s: %w", username, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+adds a transaction recovered from the pending archive to the active session of the user
+it stays in the pending archive until it has been processed
+*/
+func (m *Manager) enqueueReplayedTransaction(username string, txn *types.Transaction) error {
+	/* acquire manager lock to access sessions map */
+	m.mutex.RLock()
+	session := m.sessionsMap[username]
+	m.mutex.RUnlock()
+
+	if session == nil {
+		return ErrSessionNotFound
+	}
+
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+
+	queued, err := m.isPendingInSession(session, txn.ID)
+	if err != nil {
+		return err
+	}
+	if queued {
+		return ErrTransactionQueued
+	}
+
+	/* duration is measured from the moment the transaction is queued again */
+	txn.SessionID = session.ID
+	txn.ExecutedBy = username
+	txn.Timestamp = time.Now()
+	txn.Status = types.StatusPending
+	txn.Replayed = true
+
+	return m.AddTransaction(session, txn)
+}
+
+/* reports if a transaction is queued or being processed in a session */
+func (m *Manager) isPendingInSession(session *Session, txnID uuid.UUID) (bool, error) {
+	key := fmt.Sprintf("session:%s:txnpending", session.ID)
+	if _, err := m.redis.HGet(context.Background(), key, txnID.String()).Result(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get pending transaction: %w", err)
+	}
+	return true, nil
+}
+
+/* discards a transaction waiting in the pending archive, it is archived as cancelled */
+func (m *Manager) discardArchivedTransaction(username string, txnID uuid.UUID) (*types.Transaction, error) {
+	ctx := context.Background()
+
+	row, err := m.archivalPQ.GetPendingTransactionPQ(ctx, txnID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction from pending archive: %w", err)
+	}
+
+	/* transactions of other users are treated as if they don't exist */
+	if row.ExecutedBy != username {
+		return nil, ErrTransactionNotFound
+	}
+
+	tx, err := ConvertPendingArchiveToTransaction(row)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Status = types.StatusCancelled
+	tx.Output = "Transaction discarded by user"
+
+	params, err := ConvertTransactionResulttoStoreParams(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert discarded transaction to archive format: %w", err)
+	}
+	if _, err := m.archivalPQ.CreateResultsTransactionPQ(ctx, params); err != nil {
+		return nil, fmt.Errorf("failed to archive discarded transaction: %w", err)
+	}
+
+	if err := m.archivalPQ.DeletePendingTransactionPQ(ctx, txnID); err != nil {
+		return nil, fmt.Errorf("failed to remove discarded transaction from pending archive: %w", err)
+	}
+
+	return &tx, nil
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+	the server gate keeps transactions of a filesystem server queued (parked) while
+	the server runs max_concurrency transactions already or its daemon's circuit is open
+	parked transactions don't take a worker, so a slow server can't occupy all of them
+*/
+
+/*
+reports daemons whose circuit breaker is open
+AcquireCircuit is called when a transaction is dispatched, it may take the single trial of a half-open circuit
+ReleaseCircuit is called once the transaction finished, a trial without an outcome is given up
+*/
+type CircuitChecker interface {
+	CircuitOpen(addr string) bool
+	AcquireCircuit(addr string, txnID uuid.UUID) bool
+	ReleaseCircuit(addr string, txnID uuid.UUID)
+}
+
+/* transactions running per filesystem server */
+type serverGate struct {
+	mutex    sync.Mutex
+	inFlight map[string]int
+	circuits CircuitChecker
+}
+
+/* create a new server gate */
+func newServerGate(circuits CircuitChecker) *serverGate {
+	return &serverGate{
+		inFlight: make(map[string]int),
+		circuits: circuits,
+	}
+}
+
+/* reports if every server of a transaction can take it right now */
+func (g *serverGate) admits(txn *types.Transaction) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.admitsLocked(transactionServers(txn))
+}
+
+/* takes a slot on every server of a transaction, false (and nothing taken) if any server is busy or down */
+func (g *serverGate) acquire(txn *types.Transaction) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	servers := transactionServers(txn)
+	if !g.admitsLocked(servers) {
+		return false
+	}
+
+	for i, server := range servers {
+		if server.Remote != nil && !g.circuits.AcquireCircuit(daemonAddress(server), txn.ID) {
+			/* a trial taken on an earlier server is given back */
+			for _, taken := range servers[:i] {
+				if taken.Remote != nil {
+					g.circuits.ReleaseCircuit(daemonAddress(taken), txn.ID)
+				}
+			}
+			return false
+		}
+	}
+
+	for _, server := range servers {
+		g.inFlight[server.Path]++
+	}
+
+	return true
+}
+
+/* returns the slots taken by a transaction */
+func (g *serverGate) release(txn *types.Transaction) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for _, server := range transactionServers(txn) {
+		if server.Remote != nil {
+			g.circuits.ReleaseCircuit(daemonAddress(server), txn.ID)
+		}
+		if g.inFlight[server.Path] <= 1 {
+			delete(g.inFlight, server.Path)
+			continue
+		}
+		g.inFlight[server.Path]--
+	}
+}
+
+/* assumes caller holds the gate lock */
+func (g *serverGate) admitsLocked(servers []config.FileSystemServers) bool {
+	for _, server := range servers {
+		if server.MaxConcurrency > 0 && g.inFlight[server.Path] >= server.MaxConcurrency {
+			return false
+		}
+		if server.Remote != nil && g.circuits.CircuitOpen(daemonAddress(server)) {
+			return false
+		}
+	}
+	return true
+}
+
+/* distinct filesystem servers touched by a transaction */
+func transactionServers(txn *types.Transaction) []config.FileSystemServers {
+	var servers []config.FileSystemServers
+	seen := make(map[string]bool)
+
+	for _, p := range transactionPaths(txn) {
+		/* same lookup as the processor, the first configured server with a matching prefix serves the path */
+		for _, server := range config.BackendConfig.FileSystemServers {
+			if !strings.HasPrefix(p.path, server.Path) {
+				continue
+			}
+			if !seen[server.Path] {
+				seen[server.Path] = true
+				servers = append(servers, server)
+			}
+			break
+		}
+	}
+
+	return servers
+}
+
+/* address of the daemon serving a remote filesystem server */
+func daemonAddress(server config.FileSystemServers) string {
+	return fmt.Sprintf("%s:%d", server.Remote.Host, server.Remote.Port)
+}
@@ -0,0 +1,269 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+circuits of daemons by address, a half-open circuit grants a single trial
+reopened circuits look closed but refuse to be taken, like a trial failing between the check and the acquire
+*/
+type fakeCircuits struct {
+	open     map[string]bool
+	halfOpen map[string]bool
+	reopened map[string]bool
+	trials   map[string]uuid.UUID
+	released []string
+}
+
+func newFakeCircuits() *fakeCircuits {
+	return &fakeCircuits{
+		open:     make(map[string]bool),
+		halfOpen: make(map[string]bool),
+		reopened: make(map[string]bool),
+		trials:   make(map[string]uuid.UUID),
+	}
+}
+
+func (c *fakeCircuits) CircuitOpen(addr string) bool {
+	return c.open[addr] && !(c.halfOpen[addr] && c.trials[addr] == uuid.Nil)
+}
+
+func (c *fakeCircuits) AcquireCircuit(addr string, txnID uuid.UUID) bool {
+	if c.reopened[addr] {
+		return false
+	}
+	if !c.open[addr] {
+		return true
+	}
+	if !c.halfOpen[addr] {
+		return false
+	}
+	if c.trials[addr] != uuid.Nil {
+		return c.trials[addr] == txnID
+	}
+	c.trials[addr] = txnID
+	return true
+}
+
+func (c *fakeCircuits) ReleaseCircuit(addr string, txnID uuid.UUID) {
+	c.released = append(c.released, addr)
+	if c.trials[addr] == txnID {
+		c.trials[addr] = uuid.Nil
+	}
+}
+
+/* filesystem servers of the tests, /srv/remote and /srv/shared are served by the same daemon */
+func withServers(t *testing.T) {
+	previous := config.BackendConfig.FileSystemServers
+	config.BackendConfig.FileSystemServers = []config.FileSystemServers{
+		{Path: "/srv/limited", MaxConcurrency: 2},
+		{Path: "/srv/single", MaxConcurrency: 1},
+		{Path: "/srv/unlimited"},
+		{Path: "/srv/remote", Remote: &config.Remote{Host: "remote.test", Port: 2310}, MaxConcurrency: 1},
+		{Path: "/srv/shared", Remote: &config.Remote{Host: "remote.test", Port: 2310}},
+		{Path: "/srv/other", Remote: &config.Remote{Host: "other.test", Port: 2310}},
+	}
+	t.Cleanup(func() { config.BackendConfig.FileSystemServers = previous })
+}
+
+func TestServerGateMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		name string
+		txns []*types.Transaction
+
+		/* result of acquire for every transaction in order, then the transactions released */
+		wantAcquired []bool
+		release      []int
+
+		/* acquire for every parked transaction again after the release, entries of the others are ignored */
+		wantAfter []bool
+	}{
+		{
+			name:         "slots up to max_concurrency",
+			txns:         []*types.Transaction{gateTxn("/srv/limited/a"), gateTxn("/srv/limited/b"), gateTxn("/srv/limited/c")},
+			wantAcquired: []bool{true, true, false},
+			release:      []int{0},
+			wantAfter:    []bool{false, false, true},
+		},
+		{
+			name:         "servers are limited independently",
+			txns:         []*types.Transaction{gateTxn("/srv/single/a"), gateTxn("/srv/limited/a"), gateTxn("/srv/single/b")},
+			wantAcquired: []bool{true, true, false},
+		},
+		{
+			name:         "no limit without max_concurrency",
+			txns:         []*types.Transaction{gateTxn("/srv/unlimited/a"), gateTxn("/srv/unlimited/a"), gateTxn("/srv/unlimited/a")},
+			wantAcquired: []bool{true, true, true},
+		},
+		{
+			name:         "paths outside every server",
+			txns:         []*types.Transaction{gateTxn("/elsewhere/a"), gateTxn("/elsewhere/b")},
+			wantAcquired: []bool{true, true},
+		},
+		{
+			name: "transaction on two servers takes a slot on both",
+			txns: []*types.Transaction{
+				gateTxn("/srv/limited/a", copyFrom("/srv/single/a")),
+				gateTxn("/srv/single/b"),
+				gateTxn("/srv/limited/b"),
+				gateTxn("/srv/limited/c"),
+			},
+			wantAcquired: []bool{true, false, true, false},
+			release:      []int{0},
+			wantAfter:    []bool{false, true, false, true},
+		},
+		{
+			name: "nothing is taken when one server is full",
+			txns: []*types.Transaction{
+				gateTxn("/srv/single/a"),
+				gateTxn("/srv/limited/a", copyFrom("/srv/single/b")),
+				gateTxn("/srv/limited/b"),
+				gateTxn("/srv/limited/c"),
+			},
+			wantAcquired: []bool{true, false, true, true},
+		},
+		{
+			name:         "changeset steps on one server take a single slot",
+			txns:         []*types.Transaction{gateTxn("/srv/single/a", changeset("/srv/single/a", "/srv/single/b")), gateTxn("/srv/single/c")},
+			wantAcquired: []bool{true, false},
+			release:      []int{0},
+			wantAfter:    []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withServers(t)
+			g := newServerGate(newFakeCircuits())
+
+			for i, txn := range tt.txns {
+				admits := g.admits(txn)
+				if got := g.acquire(txn); got != tt.wantAcquired[i] {
+					t.Errorf("acquire %d = %v, want %v", i, got, tt.wantAcquired[i])
+				}
+				if admits != tt.wantAcquired[i] {
+					t.Errorf("admits %d = %v, want %v", i, admits, tt.wantAcquired[i])
+				}
+			}
+
+			if tt.wantAfter == nil {
+				return
+			}
+
+			for _, i := range tt.release {
+				g.release(tt.txns[i])
+			}
+			for i, txn := range tt.txns {
+				if tt.wantAcquired[i] {
+					continue
+				}
+				if got := g.acquire(txn); got != tt.wantAfter[i] {
+					t.Errorf("acquire %d after release = %v, want %v", i, got, tt.wantAfter[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServerGateReleaseAll(t *testing.T) {
+	withServers(t)
+	g := newServerGate(newFakeCircuits())
+
+	txns := []*types.Transaction{gateTxn("/srv/limited/a"), gateTxn("/srv/limited/b", copyFrom("/srv/single/a"))}
+	for _, txn := range txns {
+		if !g.acquire(txn) {
+			t.Fatalf("acquire %s failed", txn.TargetPath)
+		}
+	}
+	for _, txn := range txns {
+		g.release(txn)
+	}
+
+	/* servers without a running transaction don't keep a counter */
+	if len(g.inFlight) != 0 {
+		t.Errorf("in flight = %v, want none", g.inFlight)
+	}
+}
+
+func TestServerGateCircuits(t *testing.T) {
+	tests := []struct {
+		name     string
+		open     []string
+		halfOpen []string
+		reopened []string
+		txns     []*types.Transaction
+
+		wantAcquired []bool
+
+		/* daemons whose trial was given back while acquiring */
+		wantReleased []string
+	}{
+		{
+			name:         "open circuit parks transactions",
+			open:         []string{"remote.test:2310"},
+			txns:         []*types.Transaction{gateTxn("/srv/remote/a"), gateTxn("/srv/other/a"), gateTxn("/srv/limited/a")},
+			wantAcquired: []bool{false, true, true},
+		},
+		{
+			name:         "half-open circuit admits a single trial",
+			open:         []string{"other.test:2310"},
+			halfOpen:     []string{"other.test:2310"},
+			txns:         []*types.Transaction{gateTxn("/srv/other/a"), gateTxn("/srv/other/b")},
+			wantAcquired: []bool{true, false},
+		},
+		{
+			name:         "trial spans the servers of its daemon",
+			open:         []string{"remote.test:2310"},
+			halfOpen:     []string{"remote.test:2310"},
+			txns:         []*types.Transaction{gateTxn("/srv/shared/a", copyFrom("/srv/remote/a")), gateTxn("/srv/shared/b")},
+			wantAcquired: []bool{true, false},
+		},
+		{
+			name:         "trial on one daemon is given back when the other reopened",
+			open:         []string{"remote.test:2310"},
+			halfOpen:     []string{"remote.test:2310"},
+			reopened:     []string{"other.test:2310"},
+			txns:         []*types.Transaction{gateTxn("/srv/remote/a", copyFrom("/srv/other/a")), gateTxn("/srv/remote/b")},
+			wantAcquired: []bool{false, true},
+			wantReleased: []string{"remote.test:2310"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withServers(t)
+			circuits := newFakeCircuits()
+			for _, addr := range tt.open {
+				circuits.open[addr] = true
+			}
+			for _, addr := range tt.halfOpen {
+				circuits.halfOpen[addr] = true
+			}
+			for _, addr := range tt.reopened {
+				circuits.reopened[addr] = true
+			}
+			g := newServerGate(circuits)
+
+			for i, txn := range tt.txns {
+				if got := g.acquire(txn); got != tt.wantAcquired[i] {
+					t.Errorf("acquire %d = %v, want %v", i, got, tt.wantAcquired[i])
+				}
+			}
+
+			if len(circuits.released) != len(tt.wantReleased) {
+				t.Fatalf("released %v, want %v", circuits.released, tt.wantReleased)
+			}
+			for i := range tt.wantReleased {
+				if circuits.released[i] != tt.wantReleased[i] {
+					t.Errorf("released %v, want %v", circuits.released, tt.wantReleased)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package session
+
+import (
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+reports the queue depth of every session (in round robin order) and the oldest waiting transaction
+oldest is nil when every queue is empty
+*/
+func (m *Manager) QueueStats() ([]SessionQueueStats, *PendingTransactionStats) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := time.Now()
+	stats := make([]SessionQueueStats, 0, m.sessionOrder.Len())
+	var oldest *PendingTransactionStats
+
+	for element := m.sessionOrder.Front(); element != nil; element = element.Next() {
+		session := element.Value.(*Session)
+
+		session.Mutex.Lock()
+		entry := SessionQueueStats{
+			SessionID: session.ID.String(),
+			Username:  session.Username,
+			Depth:     session.TransactionQueue.Len(),
+		}
+
+		for node := session.TransactionQueue.Front(); node != nil; node = node.Next() {
+			txn, ok := node.Value.(*types.Transaction)
+			if !ok {
+				continue
+			}
+
+			if m.gate.ready(txn.ID) && m.servers.admits(txn) {
+				entry.Ready++
+			}
+
+			if oldest == nil || txn.Timestamp.Before(oldest.QueuedAt) {
+				oldest = &PendingTransactionStats{
+					ID:         txn.ID.String(),
+					SessionID:  session.ID.String(),
+					Username:   session.Username,
+					Operation:  txn.Operation,
+					TargetPath: txn.TargetPath,
+					Priority:   txn.Priority,
+					QueuedAt:   txn.Timestamp,
+					WaitingMs:  now.Sub(txn.Timestamp).Milliseconds(),
+				}
+			}
+		}
+		session.Mutex.Unlock()
+
+		stats = append(stats, entry)
+	}
+
+	return stats, oldest
+}
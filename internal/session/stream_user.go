@@ -53,13 +53,16 @@ func (m *Manager) sendCurrentSession(conn *websocket.Conn, sessionID string) err
 }
 
 /* send data regarding current session */
-func (m *Manager) listenForSessionChanges(ctx context.Context, conn *websocket.Conn, sessionID string) {
+func (m *Manager) listenForSessionChanges(ctx context.Context, conn *websocket.Conn, username, sessionID string) {
 	/* subscribe to both keyspace and keyevent notifications */
 	keyspacePattern := fmt.Sprintf("__keyspace@0__:session:%s", sessionID)
 	keyeventPattern := fmt.Sprintf("__keyevent@0__:hset:session:%s", sessionID)
 
-	/* subscribe to Redis keyspace and keyevent */
-	pubsub, err := m.redis.PSubscribe(ctx, keyspacePattern, keyeventPattern)
+	/* notices for the user (like grants about to expire) */
+	noticesChannel := fmt.Sprintf("user:%s:notices", username)
+
+	/* subscribe to Redis keyspace, keyevent and user notices */
+	pubsub, err := m.redis.PSubscribe(ctx, keyspacePattern, keyeventPattern, noticesChannel)
 	if err != nil {
 		m.errCh <- fmt.Errorf("failed to subscribe to redis events: %w", err)
 		return
@@ -76,6 +79,14 @@ func (m *Manager) listenForSessionChanges(ctx context.Context, conn *websocket.C
 		case <-ctx.Done():
 			return
 		case msg := <-ch:
+			/* notices are forwarded as they are */
+			if msg.Channel == noticesChannel {
+				if err := m.handleUserNotice(conn, sessionID, msg); err != nil {
+					m.errCh <- fmt.Errorf("error handling user notice: %w", err)
+				}
+				continue
+			}
+
 			/* changes in session stored in Redis detected; handle the event */
 			if err := m.handleSessionChangeEvent(conn, sessionID, msg); err != nil {
 				m.errCh <- fmt.Errorf("error handling session change: %w", err)
@@ -114,6 +125,25 @@ func (m *Manager) handleSessionChangeEvent(conn *websocket.Conn, sessionID strin
 	return conn.WriteJSON(message)
 }
 
+/* forward a notice published for the user */
+func (m *Manager) handleUserNotice(conn *websocket.Conn, sessionID string, msg *redis.Message) error {
+	var notice GrantNotice
+	if err := json.Unmarshal([]byte(msg.Payload), &notice); err != nil {
+		return fmt.Errorf("failed to unmarshal notice: %w", err)
+	}
+
+	message := StreamMessage{
+		Type: notice.Notice,
+		Data: map[string]any{
+			"session_id": sessionID,
+			"grant":      notice.Grant,
+		},
+		Timestamp: time.Now(),
+	}
+
+	return conn.WriteJSON(message)
+}
+
 /* ==== User Transaction List ==== */
 
 /* send current user results transactions */
@@ -154,24 +184,10 @@ func (m *Manager) sendCurrentUserTransactionsResults(conn *websocket.Conn, sessi
 
 /* send current user pending transactions */
 func (m *Manager) sendCurrentUserTransactionsPending(conn *websocket.Conn, sessionID string, limit int) error {
-	ctx := context.Background()
-
-	/* get latest transactions from Redis */
-	key := fmt.Sprintf("session:%s:txpending", sessionID)
-	values, err := m.redis.LRange(ctx, key, int64(-limit), -1).Result()
+	/* get latest pending transactions from Redis */
+	transactions, err := m.getPendingTransactionsRedis(sessionID, limit)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction results: %w", err)
-	}
-
-	/* convert each JSON string back into a Transaction */
-	transactions := make([]types.Transaction, 0, len(values))
-	for _, val := range values {
-		var tx types.Transaction
-		if err := json.Unmarshal([]byte(val), &tx); err != nil {
-			/* skip malformed results */
-			continue
-		}
-		transactions = append(transactions, tx)
+		return err
 	}
 
 	/* prepare the message payload */
@@ -223,11 +239,14 @@ func (m *Manager) listenForTransactionsChangesResults(ctx context.Context, conn
 /* listen for pending transaction changes in Redis */
 func (m *Manager) listenForTransactionsChangesPending(ctx context.Context, conn *websocket.Conn, sessionID string) {
 	/* subscribe to both keyspace and keyevent notifications */
-	keyspacePattern := fmt.Sprintf("__keyspace@0__:session:%s:txpending", sessionID)
-	keyeventPattern := fmt.Sprintf("__keyevent@0__:rpush:session:%s:txpending", sessionID)
+	keyspacePattern := fmt.Sprintf("__keyspace@0__:session:%s:txnpending", sessionID)
+	keyeventPattern := fmt.Sprintf("__keyevent@0__:hset:session:%s:txnpending", sessionID)
 
-	/* subscribe to Redis keyspace and keyevent */
-	pubsub, err := m.redis.PSubscribe(ctx, keyspacePattern, keyeventPattern)
+	/* transaction events (like cancellations) published by the session manager */
+	eventsChannel := fmt.Sprintf("session:%s:txnevents", sessionID)
+
+	/* subscribe to Redis keyspace, keyevent and transaction events */
+	pubsub, err := m.redis.PSubscribe(ctx, keyspacePattern, keyeventPattern, eventsChannel)
 	if err != nil {
 		m.errCh <- fmt.Errorf("failed to subscribe to redis events: %w", err)
 		return
@@ -297,24 +316,31 @@ func (m *Manager) handleTransactionChangeEventResults(conn *websocket.Conn, sess
 
 /* handle transaction pending change event */
 func (m *Manager) handleTransactionChangeEventPending(conn *websocket.Conn, sessionID string, msg *redis.Message) error {
-	ctx := context.Background()
+	/* transaction events are forwarded as they are */
+	if msg.Channel == fmt.Sprintf("session:%s:txnevents", sessionID) {
+		var event TransactionEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			return fmt.Errorf("failed to unmarshal transaction event: %w", err)
+		}
 
-	/* get latest transactions */
-	key := fmt.Sprintf("session:%s:txpending", sessionID)
-	values, err := m.redis.LRange(ctx, key, -100, -1).Result()
-	if err != nil {
-		return fmt.Errorf("failed to get pending transactions: %w", err)
+		message := StreamMessage{
+			Type: "transaction_" + event.Event,
+			Data: map[string]any{
+				"session_id":   sessionID,
+				"txn_id":       event.TxnID,
+				"transaction":  event.Transaction,
+				"event_source": "transaction_event",
+			},
+			Timestamp: time.Now(),
+		}
+
+		return conn.WriteJSON(message)
 	}
 
-	/* convert each JSON string back into a Transaction */
-	transactions := make([]types.Transaction, 0, len(values))
-	for _, val := range values {
-		var tx types.Transaction
-		if err := json.Unmarshal([]byte(val), &tx); err != nil {
-			/* skip malformed results */
-			continue
-		}
-		transactions = append(transactions, tx)
+	/* get latest pending transactions */
+	transactions, err := m.getPendingTransactionsRedis(sessionID, 100)
+	if err != nil {
+		return err
 	}
 
 	/* prepare the message payload */
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 
@@ -91,3 +92,53 @@ func (m *Manager) SaveTransactionRedisList(session *Session, txResult *types.Tra
 	/* push the transaction result in the back of the list */
 	return m.redis.RPush(ctx, key, resultBytes).Err()
 }
+
+/* returns pending transactions of a session from Redis in the order they were scheduled */
+func (m *Manager) getPendingTransactionsRedis(sessionID string, limit int) ([]types.Transaction, error) {
+	ctx := context.Background()
+
+	key := fmt.Sprintf("session:%s:txnpending", sessionID)
+
+	values, err := m.redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transactions: %w", err)
+	}
+
+	/* hash fields have no order, sort them by the time they were scheduled */
+	pending := make([]types.Transaction, 0, len(values))
+	for _, val := range values {
+		var tx types.Transaction
+		if err := json.Unmarshal([]byte(val), &tx); err != nil {
+			/* skip malformed transactions */
+			continue
+		}
+		pending = append(pending, tx)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Timestamp.Before(pending[j].Timestamp)
+	})
+
+	if limit > 0 && len(pending) > limit {
+		pending = pending[len(pending)-limit:]
+	}
+
+	return pending, nil
+}
+
+/* publish an event about a transaction on session:<sessionID>:txnevents */
+func (m *Manager) publishTransactionEvent(session *Session, event string, tx *types.Transaction) error {
+	ctx := context.Background()
+
+	channel := fmt.Sprintf("session:%s:txnevents", session.ID)
+
+	payload, err := json.Marshal(TransactionEvent{
+		Event:       event,
+		TxnID:       tx.ID.String(),
+		Transaction: tx,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction event: %w", err)
+	}
+
+	return m.redis.Publish(ctx, channel, payload).Err()
+}
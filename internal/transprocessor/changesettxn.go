@@ -0,0 +1,299 @@
+package transprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+handles changeset execution
+steps are applied in order, each on whichever filesystem server serves its path
+if a step fails, every step written so far is restored from its before-image in reverse order
+rollback isn't bound to ctx, a cancelled changeset is still restored
+*/
+func (p *PermProcessor) HandleChangeset(ctx context.Context, txn *types.Transaction) error {
+	start := time.Now()
+	defer func() {
+		txn.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	/* status of transaction is successful (it was processed), execution depends on steps */
+	txn.Status = types.StatusSuccess
+	txn.ExecStatus = false
+	txn.ErrorMsg = ""
+	txn.Output = ""
+	txn.Warnings = nil
+
+	if len(txn.Steps) == 0 {
+		txn.ErrorMsg = "changeset has no steps"
+		return nil
+	}
+
+	/* a retried changeset starts over, the previous attempt was rolled back completely */
+	for i := range txn.Steps {
+		step := &txn.Steps[i]
+		step.Status = types.StepPending
+		step.ErrorMsg = ""
+		step.Warnings = nil
+		step.BeforeACL = nil
+		step.ACL = nil
+		for j := range step.Entries {
+			step.Entries[j].Success = false
+			step.Entries[j].Error = ""
+		}
+	}
+
+	failedStep := -1
+	var stepErr error
+	for i := range txn.Steps {
+		stepErr = p.applyChangesetStep(ctx, txn.ID, &txn.Steps[i])
+		if txn.Steps[i].Status != types.StepApplied {
+			failedStep = i
+			break
+		}
+	}
+
+	if failedStep < 0 {
+		for _, step := range txn.Steps {
+			for _, warning := range step.Warnings {
+				txn.Warnings = append(txn.Warnings, fmt.Sprintf("%s: %s", step.TargetPath, warning))
+			}
+		}
+		txn.ExecStatus = true
+		txn.Output = fmt.Sprintf("Changeset applied to %d paths", len(txn.Steps))
+		return nil
+	}
+
+	for i := failedStep + 1; i < len(txn.Steps); i++ {
+		txn.Steps[i].Status = types.StepSkipped
+	}
+
+	/* the failed step itself may have been written partially */
+	rollbackCtx := context.WithoutCancel(ctx)
+	failedWritten := stepWritten(&txn.Steps[failedStep])
+	rollbackFailed := 0
+	for i := failedStep; i >= 0; i-- {
+		step := &txn.Steps[i]
+
+		/* outcome of the step couldn't be determined, it may still be applied */
+		if step.Status == types.StepRollbackFailed {
+			rollbackFailed++
+			continue
+		}
+
+		if !stepWritten(step) {
+			continue
+		}
+
+		if err := p.rollbackChangesetStep(rollbackCtx, txn.ID, step); err != nil {
+			rollbackFailed++
+			step.Status = types.StepRollbackFailed
+			step.ErrorMsg = fmt.Sprintf("failed to roll back: %s", err.Error())
+			continue
+		}
+
+		if i != failedStep {
+			step.Status = types.StepRolledBack
+		}
+	}
+
+	failed := txn.Steps[failedStep]
+	txn.ErrorMsg = fmt.Sprintf("step %d (%s) failed: %s", failedStep+1, failed.TargetPath, failed.ErrorMsg)
+	if rollbackFailed > 0 {
+		txn.ErrorMsg = fmt.Sprintf("%s; %d steps couldn't be rolled back", txn.ErrorMsg, rollbackFailed)
+		return nil
+	}
+
+	/*
+		daemon couldn't be reached in time and nothing is left applied, let the processor decide about retrying
+		a failed step that turned out to be applied isn't retried, even though it was rolled back
+	*/
+	if IsRetryable(stepErr) && !failedWritten {
+		return stepErr
+	}
+
+	txn.Output = "Changeset rolled back"
+
+	return nil
+}
+
+/*
+applies the entries of a single step and captures its before and after images
+returns an error only if a daemon couldn't be reached, the step status tells whether it applied
+*/
+func (p *PermProcessor) applyChangesetStep(ctx context.Context, txnID uuid.UUID, step *types.ChangesetStep) error {
+	isRemote, host, port, found, absolutePath := FindServerFromPath(step.TargetPath)
+	if !found {
+		step.Status = types.StepFailed
+		step.ErrorMsg = "filesystem of given path doesn't exist"
+		return nil
+	}
+
+	if isRemote {
+		return p.applyRemoteChangesetStep(ctx, txnID, host, port, step, absolutePath)
+	}
+
+	/* lock the file path for thread safety (ensure unlock even on panic) */
+	lock := getPathLock(absolutePath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	change, err := applyEntriesToPath(absolutePath, step.Entries)
+	if err != nil {
+		step.Status = types.StepFailed
+		step.ErrorMsg = err.Error()
+		for i := range step.Entries {
+			step.Entries[i].Success = false
+			step.Entries[i].Error = step.ErrorMsg
+		}
+		return nil
+	}
+
+	failed := 0
+	for i := range step.Entries {
+		entry := &step.Entries[i]
+		if change.entryErrs[i] != nil {
+			entry.Success = false
+			entry.Error = change.entryErrs[i].Error()
+			failed++
+			continue
+		}
+		entry.Success = true
+		entry.Error = ""
+	}
+
+	if change.written {
+		step.BeforeACL = change.before
+		step.ACL = change.after
+	}
+	step.Warnings = change.warnings
+
+	if failed > 0 {
+		step.Status = types.StepFailed
+		step.ErrorMsg = fmt.Sprintf("%d of %d ACL entries failed", failed, len(step.Entries))
+		return nil
+	}
+
+	step.Status = types.StepApplied
+
+	return nil
+}
+
+/*
+applies a single step via the daemon serving its path
+a step without a before-image couldn't be rolled back, so it isn't applied at all
+*/
+func (p *PermProcessor) applyRemoteChangesetStep(ctx context.Context, txnID uuid.UUID, host string, port int, step *types.ChangesetStep, absolutePath string) error {
+	sub := types.Transaction{
+		ID:         txnID,
+		Operation:  types.OperationSetACL,
+		TargetPath: step.TargetPath,
+		Entries:    step.Entries,
+	}
+	before, err := p.applyRemoteEntries(ctx, host, port, &sub, absolutePath, true)
+	p.recordOutcome(host, port, txnID, err)
+	if err != nil {
+		step.Status = types.StepFailed
+		step.ErrorMsg = err.Error()
+
+		/* the daemon may have applied a request that timed out, lost its connection or was cancelled */
+		if before != nil && (IsRetryable(err) || ctx.Err() != nil) {
+			p.resolveRemoteChangesetStep(context.WithoutCancel(ctx), txnID, host, port, step, before, absolutePath)
+		}
+		return err
+	}
+
+	/* the after-image is only used to detect later changes, rollback works without it */
+	step.BeforeACL = before
+	step.ACL = sub.ACL
+	step.Warnings = sub.Warnings
+
+	if !sub.ExecStatus {
+		step.Status = types.StepFailed
+		step.ErrorMsg = sub.ErrorMsg
+		return nil
+	}
+
+	step.Status = types.StepApplied
+
+	return nil
+}
+
+/*
+reads back the ACL of a step whose outcome is unknown
+a changed ACL is kept as after-image so the step is rolled back like any written step
+if it can't be read, the step is left as not rolled back and the changeset isn't retried
+*/
+func (p *PermProcessor) resolveRemoteChangesetStep(ctx context.Context, txnID uuid.UUID, host string, port int, step *types.ChangesetStep, before []types.ACLRule, absolutePath string) {
+	current, err := p.ReadRemoteACL(ctx, host, port, txnID.String(), absolutePath)
+	if err != nil {
+		step.Status = types.StepRollbackFailed
+		step.ErrorMsg = fmt.Sprintf("%s; outcome unknown, failed to read ACL back: %s", step.ErrorMsg, err.Error())
+		return
+	}
+
+	if posixacl.EqualRules(before, current) {
+		return
+	}
+
+	step.BeforeACL = before
+	step.ACL = current
+}
+
+/* restores the before-image of a step, unless the path changed again since the step */
+func (p *PermProcessor) rollbackChangesetStep(ctx context.Context, txnID uuid.UUID, step *types.ChangesetStep) error {
+	isRemote, host, port, found, absolutePath := FindServerFromPath(step.TargetPath)
+	if !found {
+		return fmt.Errorf("filesystem of given path doesn't exist")
+	}
+
+	sub := types.Transaction{
+		ID:          txnID,
+		Operation:   types.OperationRestoreACL,
+		TargetPath:  step.TargetPath,
+		ACL:         step.BeforeACL,
+		ExpectedACL: step.ACL,
+	}
+
+	var err error
+	if isRemote {
+		err = p.HandleRemoteRestoreACL(ctx, host, port, &sub, absolutePath)
+	} else {
+		err = p.HandleLocalRestoreACL(&sub, absolutePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !sub.ExecStatus {
+		return errors.New(sub.ErrorMsg)
+	}
+
+	return nil
+}
+
+/* reports if any entry of a step was applied, so the path may differ from its before-image */
+func stepWritten(step *types.ChangesetStep) bool {
+	if len(step.BeforeACL) == 0 {
+		return false
+	}
+
+	/* a step with an unknown outcome has no successful entries, the ACL read back tells */
+	if len(step.ACL) != 0 && !posixacl.EqualRules(step.BeforeACL, step.ACL) {
+		return true
+	}
+
+	for _, entry := range step.Entries {
+		if entry.Success {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package transprocessor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/*
+handles copyacl transaction execution
+the ACL of the source path is read at execution time and replaces the ACL of the target
+source and target may be served by different filesystem servers
+*/
+func (p *PermProcessor) HandleCopyACL(ctx context.Context, isRemote bool, host string, port int, txn *types.Transaction, absolutePath string) error {
+	start := time.Now()
+
+	rules, err := p.ReadACL(ctx, txn.SourcePath)
+	if IsRetryable(err) {
+		/* daemon of the source couldn't be reached in time, let the processor decide about retrying */
+		return err
+	}
+	if err != nil {
+		/* status of transaction is successful (it was processed), execution failed */
+		txn.Status = types.StatusSuccess
+		txn.ExecStatus = false
+		txn.ErrorMsg = fmt.Sprintf("failed to read ACL of source path: %s", err.Error())
+		txn.DurationMs = time.Since(start).Milliseconds()
+		return nil
+	}
+
+	/* copy is a restore of the source image without any expectation on the target */
+	txn.ACL = rules
+	txn.ExpectedACL = nil
+
+	if isRemote {
+		if err := p.HandleRemoteRestoreACL(ctx, host, port, txn, absolutePath); err != nil {
+			return err
+		}
+	} else {
+		if err := p.HandleLocalRestoreACL(txn, absolutePath); err != nil {
+			return err
+		}
+	}
+
+	if txn.ExecStatus {
+		txn.Output = fmt.Sprintf("ACL copied from %s", txn.SourcePath)
+	}
+	txn.DurationMs = time.Since(start).Milliseconds()
+
+	return nil
+}
+
+/* reads access and default ACL of a path on whichever filesystem server serves it */
+func (p *PermProcessor) ReadACL(ctx context.Context, targetPath string) ([]types.ACLRule, error) {
+	isRemote, host, port, found, absolutePath := FindServerFromPath(targetPath)
+	if !found {
+		return nil, fmt.Errorf("filesystem of given path doesn't exist")
+	}
+
+	if isRemote {
+		return p.ReadRemoteACL(ctx, host, port, "", absolutePath)
+	}
+
+	return ReadLocalACL(absolutePath)
+}
+
+/* access entries of an ACL image, default entries dropped */
+func accessRules(rules []types.ACLRule) []types.ACLRule {
+	access := make([]types.ACLRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.IsDefault {
+			access = append(access, rule)
+		}
+	}
+	return access
+}
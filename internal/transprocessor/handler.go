@@ -0,0 +1,260 @@
+package transprocessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/api/middleware"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
+)
+
+/*
+	handlers here read the filesystem directly but never write to it
+	changes are always scheduled as transactions
+*/
+
+/*
+POST handler for previewing a transaction: current ACL, resulting ACL, mask and effective rights
+the request is validated like a scheduled one, so only paths a transaction could touch are read
+*/
+func PreviewTransactionHandler(validator *validation.Validator, p *PermProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ScheduleTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if req.Operation != "" && req.Operation != types.OperationSetACL {
+			http.Error(w, "Only setfacl transactions can be previewed", http.StatusBadRequest)
+			return
+		}
+
+		if len(req.Entries) == 0 {
+			http.Error(w, "Transaction has no ACL entries", http.StatusBadRequest)
+			return
+		}
+
+		/* the operation may be omitted, a preview is always a setfacl */
+		req.Operation = types.OperationSetACL
+
+		/* rejects paths escaping the filesystem servers (e.g. "..") and paths that don't exist */
+		if err := validator.ValidateRequest(&req); err != nil {
+			var fieldErrs validation.FieldErrors
+			if errors.As(err, &fieldErrs) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				if err := json.NewEncoder(w).Encode(map[string]any{
+					"message": "Invalid transaction request",
+					"errors":  fieldErrs,
+				}); err != nil {
+					p.errCh <- fmt.Errorf("failed to encode validation errors: %w", err)
+				}
+				return
+			}
+
+			p.errCh <- fmt.Errorf("failed to validate preview request: %w", err)
+			http.Error(w, "Failed to validate transaction request", http.StatusBadGateway)
+			return
+		}
+
+		isRemote, host, port, found, absolutePath := FindServerFromPath(req.TargetPath)
+		if !found {
+			http.Error(w, "Filesystem of given path doesn't exist", http.StatusNotFound)
+			return
+		}
+
+		/* read the current ACL the same way the transaction would be executed */
+		var facl *posixacl.FileACL
+		var err error
+		if isRemote {
+			facl, err = p.ReadRemoteFileACL(r.Context(), host, port, "", absolutePath)
+		} else {
+			facl, err = posixacl.Read(absolutePath)
+		}
+		if err != nil {
+			zap.L().Warn("Failed to read ACL for preview",
+				zap.String("targetPath", req.TargetPath),
+				zap.Error(err),
+			)
+			if errors.Is(err, fs.ErrNotExist) {
+				http.Error(w, "Target path doesn't exist", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to read current ACL", http.StatusBadGateway)
+			return
+		}
+
+		response := PreviewResponse{
+			TargetPath: req.TargetPath,
+			Current:    facl.Rules(),
+			Entries:    make([]types.ACLEntry, len(req.Entries)),
+			Recursive:  req.Recursive,
+		}
+
+		/* apply entries in memory, rejected entries are reported like they would be on execution */
+		copy(response.Entries, req.Entries)
+		for i := range response.Entries {
+			entry := &response.Entries[i]
+			if err := facl.Apply(*entry); err != nil {
+				entry.Success = false
+				entry.Error = err.Error()
+				continue
+			}
+			entry.Success = true
+			entry.Error = ""
+		}
+
+		if err := facl.Finalize(); err != nil {
+			http.Error(w, "Resulting ACL is invalid: "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		applied := make([]types.ACLEntry, 0, len(response.Entries))
+		for _, entry := range response.Entries {
+			if entry.Success {
+				applied = append(applied, entry)
+			}
+		}
+
+		response.Resulting = facl.Rules()
+		response.Effective = facl.EffectiveRights()
+		response.Warnings = facl.MaskWarnings(applied)
+		if mask, ok := facl.Access.Mask(); ok {
+			response.Mask = mask.String()
+		}
+		if mask, ok := facl.Default.Mask(); ok {
+			response.DefaultMask = mask.String()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			zap.L().Error("Failed to encode response for preview request",
+				zap.Error(err),
+			)
+			http.Error(w, "Failed to encode response for preview request", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+/*
+POST handler for reverting a processed transaction
+schedules a restoreacl transaction that writes back the ACL captured before the change
+*/
+func RevertTransactionHandler(sessionManager *session.Manager, p *PermProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		/* extract username from JWT Token */
+		username, ok := r.Context().Value(middleware.ContextKeyUsername).(string)
+		if !ok {
+			http.Error(w, "Invalid user context", http.StatusInternalServerError)
+			return
+		}
+
+		txnID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+			return
+		}
+
+		original, err := sessionManager.FindTransactionResult(username, txnID)
+		if err != nil {
+			if errors.Is(err, session.ErrTransactionNotFound) {
+				http.Error(w, "Transaction not found", http.StatusNotFound)
+				return
+			}
+			zap.L().Error("Failed to look up transaction for revert",
+				zap.String("txnID", txnID.String()),
+				zap.Error(err),
+			)
+			http.Error(w, "Failed to look up transaction", http.StatusInternalServerError)
+			return
+		}
+
+		/* only changes with a captured before and after image can be reverted */
+		switch original.Operation {
+		case types.OperationSetACL, types.OperationRestoreACL, types.OperationCopyACL:
+			/* these capture before and after images */
+		default:
+			http.Error(w, "Only ACL changes can be reverted", http.StatusUnprocessableEntity)
+			return
+		}
+		if original.Recursive {
+			http.Error(w, "Recursive transactions can't be reverted", http.StatusUnprocessableEntity)
+			return
+		}
+		if len(original.BeforeACL) == 0 || len(original.ACL) == 0 {
+			http.Error(w, "Transaction didn't change the ACL or has no before-image", http.StatusUnprocessableEntity)
+			return
+		}
+
+		isRemote, host, port, found, absolutePath := FindServerFromPath(original.TargetPath)
+		if !found {
+			http.Error(w, "Filesystem of given path doesn't exist", http.StatusNotFound)
+			return
+		}
+
+		/* refuse early if something else changed the ACL, the processor checks again on execution */
+		var current []types.ACLRule
+		if isRemote {
+			current, err = p.ReadRemoteACL(r.Context(), host, port, txnID.String(), absolutePath)
+		} else {
+			current, err = ReadLocalACL(absolutePath)
+		}
+		if err != nil {
+			zap.L().Warn("Failed to read ACL for revert",
+				zap.String("targetPath", original.TargetPath),
+				zap.Error(err),
+			)
+			http.Error(w, "Failed to read current ACL", http.StatusBadGateway)
+			return
+		}
+
+		if !posixacl.EqualRules(current, original.ACL) {
+			http.Error(w, "ACL of target path has changed since the transaction", http.StatusConflict)
+			return
+		}
+
+		tx := types.Transaction{
+			ID:          uuid.New(),
+			Timestamp:   time.Now(),
+			Operation:   types.OperationRestoreACL,
+			TargetPath:  original.TargetPath,
+			Entries:     []types.ACLEntry{},
+			Status:      types.StatusPending,
+			ACL:         original.BeforeACL,
+			ExpectedACL: original.ACL,
+			RevertOf:    &original.ID,
+		}
+
+		if err := sessionManager.ScheduleTransaction(username, &tx); err != nil {
+			if errors.Is(err, session.ErrSessionNotFound) {
+				http.Error(w, "Session not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to add transaction", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(map[string]string{
+			"message": "Revert scheduled",
+			"txn_id":  tx.ID.String(),
+		}); err != nil {
+			zap.L().Error("Failed to encode response for revert request",
+				zap.Error(err),
+			)
+		}
+	}
+}
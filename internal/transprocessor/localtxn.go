@@ -1,12 +1,16 @@
 package transprocessor
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io/fs"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
 )
 
@@ -19,64 +23,352 @@ func getPathLock(path string) *sync.Mutex {
 	return mtx.(*sync.Mutex)
 }
 
-/* handles local transaction execution (change permissions via mounts) */
-func (p *PermProcessor) HandleLocalTransaction(txn *types.Transaction, absolutePath string) error {
-	aclEntry := BuildACLEntry(txn.Entries)
+/* upper bound on path failures kept on a recursive transaction */
+const maxRecordedFailures = 1000
 
+/*
+handles local transaction execution (change permissions via mounts)
+a recursive walk stops at the next path once ctx is cancelled, which is the only error returned
+*/
+func (p *PermProcessor) HandleLocalTransaction(ctx context.Context, txn *types.Transaction, absolutePath string) error {
 	/* lock the file path for thread safety (ensure unlock even on panic) */
 	lock := getPathLock(absolutePath)
 	lock.Lock()
 	defer lock.Unlock()
 
-	/* execute the ACL modifications with acl commands */
-	var cmd *exec.Cmd
-	switch txn.Entries.Action {
-	case "add", "modify":
-		cmd = exec.Command("setfacl", "-m", aclEntry, absolutePath)
-	case "remove":
-		cmd = exec.Command("setfacl", "-x", aclEntry, absolutePath)
-	default:
-		// sendResponse(conn, false, "Unsupported action: "+req.Action)
-		txn.ErrorMsg = fmt.Sprintf("unsupported ACL action: %s", txn.Entries.Action)
-	}
-
 	start := time.Now()
+	defer func() {
+		txn.DurationMs = time.Since(start).Milliseconds()
+	}()
 
-	output, err := cmd.CombinedOutput()
+	/* status of transaction is successful (it was processed), execution depends on entries */
+	txn.Status = types.StatusSuccess
 
-	duration := time.Since(start).Milliseconds()
+	if len(txn.Entries) == 0 {
+		txn.ExecStatus = false
+		txn.ErrorMsg = "transaction has no ACL entries"
+		return nil
+	}
 
-	txn.Output = string(output)
-	txn.DurationMs = duration
+	if txn.Recursive {
+		if err := applyLocalRecursive(ctx, txn, absolutePath); err != nil {
+			return fmt.Errorf("recursive walk of %s stopped: %w", absolutePath, err)
+		}
+		return nil
+	}
 
+	change, err := applyEntriesToPath(absolutePath, txn.Entries)
 	if err != nil {
-		/* status of transaction is successful but execution failed */
-		txn.Status = types.StatusSuccess
 		txn.ExecStatus = false
 		txn.ErrorMsg = err.Error()
+		for i := range txn.Entries {
+			txn.Entries[i].Success = false
+			txn.Entries[i].Error = txn.ErrorMsg
+		}
+		return nil
+	}
+
+	failed := 0
+	for i := range txn.Entries {
+		entry := &txn.Entries[i]
+		if change.entryErrs[i] != nil {
+			entry.Success = false
+			entry.Error = change.entryErrs[i].Error()
+			failed++
+			continue
+		}
+		entry.Success = true
+		entry.Error = ""
+	}
+
+	/* keep before and after images of the path so the transaction can be reverted */
+	if change.written {
+		txn.BeforeACL = change.before
+		txn.ACL = change.after
+	}
+
+	txn.Warnings = change.warnings
+
+	txn.ExecStatus = failed == 0
+	if failed > 0 {
+		txn.ErrorMsg = fmt.Sprintf("%d of %d ACL entries failed", failed, len(txn.Entries))
+	}
+
+	return nil
+}
+
+/*
+walks the subtree under absolutePath and applies the entries to every file like setfacl -R
+symbolic links below the root are skipped, default entries are only applied to directories
+a failing path is recorded and the walk carries on, only cancellation of ctx ends it early
+*/
+func applyLocalRecursive(ctx context.Context, txn *types.Transaction, absolutePath string) error {
+	/* per entry count of paths it failed and succeeded on */
+	entryFailures := make([]int, len(txn.Entries))
+	entryApplied := make([]int, len(txn.Entries))
+
+	/* the same mask warning usually repeats on every path, keep it once */
+	seenWarnings := make(map[string]bool)
+	failedPaths := 0
+	visited := 0
+
+	recordFailure := func(path string, err error) {
+		failedPaths++
+		if len(txn.Failures) < maxRecordedFailures {
+			txn.Failures = append(txn.Failures, types.PathFailure{
+				Path:  path,
+				Error: err.Error(),
+			})
+		}
+	}
+
+	walkErr := filepath.WalkDir(absolutePath, func(path string, d fs.DirEntry, err error) error {
+		/* cancelled (watchdog deadline or shutdown); the walk ends here */
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		if err != nil {
+			/* unreadable directory; record it and keep walking its siblings */
+			recordFailure(path, err)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		/* the target itself may be a symlink, links inside the tree are never followed */
+		if d.Type()&fs.ModeSymlink != 0 && path != absolutePath {
+			return nil
+		}
+
+		/* files can't carry a default ACL, so only the access entries apply to them */
+		entries := txn.Entries
+		indexes := make([]int, 0, len(txn.Entries))
+		if !d.IsDir() {
+			entries = make([]types.ACLEntry, 0, len(txn.Entries))
+			for i, entry := range txn.Entries {
+				if entry.IsDefault {
+					continue
+				}
+				entries = append(entries, entry)
+				indexes = append(indexes, i)
+			}
+			if len(entries) == 0 {
+				return nil
+			}
+		} else {
+			for i := range txn.Entries {
+				indexes = append(indexes, i)
+			}
+		}
+
+		visited++
+
+		change, err := applyEntriesToPath(path, entries)
+		if err != nil {
+			for _, i := range indexes {
+				entryFailures[i]++
+			}
+			recordFailure(path, err)
+			return nil
+		}
+
+		for _, warning := range change.warnings {
+			if !seenWarnings[warning] {
+				seenWarnings[warning] = true
+				txn.Warnings = append(txn.Warnings, warning)
+			}
+		}
+
+		var pathErrs []string
+		for j, entryErr := range change.entryErrs {
+			if entryErr != nil {
+				entryFailures[indexes[j]]++
+				pathErrs = append(pathErrs, entryErr.Error())
+				continue
+			}
+			entryApplied[indexes[j]]++
+		}
+		if len(pathErrs) > 0 {
+			recordFailure(path, errors.New(strings.Join(pathErrs, "; ")))
+		}
+
+		return nil
+	})
+
+	if walkErr != nil {
+		recordFailure(absolutePath, walkErr)
+	}
+
+	for i := range txn.Entries {
+		entry := &txn.Entries[i]
+		entry.Success = entryFailures[i] == 0
+		entry.AppliedPaths = entryApplied[i]
+		entry.Error = ""
+		if !entry.Success {
+			entry.Error = fmt.Sprintf("failed on %d paths", entryFailures[i])
+		}
+	}
+
+	txn.ExecStatus = failedPaths == 0
+	if failedPaths > 0 {
+		txn.ErrorMsg = fmt.Sprintf("%d of %d paths failed", failedPaths, visited)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		return nil
+	}
+
+	txn.Output = fmt.Sprintf("ACL applied to %d paths", visited)
+	return nil
+}
+
+/* outcome of applying entries to a single path */
+type pathChange struct {
+	/* error of each entry, nil if it applied */
+	entryErrs []error
+
+	/* whether the ACL was written at all */
+	written bool
+
+	/* ACL of the path before and after the change */
+	before []types.ACLRule
+	after  []types.ACLRule
+
+	/* applied entries narrowed down by the mask */
+	warnings []string
+}
+
+/*
+applies entries to a single path and writes access and default ACL together
+a failed write leaves the path as it was, so it never holds a half applied transaction
+returns an error if the path couldn't be changed at all
+*/
+func applyEntriesToPath(absolutePath string, entries []types.ACLEntry) (*pathChange, error) {
+	/* read current access and default ACL straight from the xattrs */
+	facl, err := posixacl.Read(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL: %w", err)
+	}
+
+	change := &pathChange{
+		entryErrs: make([]error, len(entries)),
+		before:    facl.Rules(),
+	}
+
+	failed := 0
+	applied := make([]types.ACLEntry, 0, len(entries))
+	for i, entry := range entries {
+		if err := facl.Apply(entry); err != nil {
+			change.entryErrs[i] = err
+			failed++
+			continue
+		}
+		applied = append(applied, entry)
+	}
 
-		txn.ErrorMsg = fmt.Sprintf("setfacl failed: %s, output: %s", err.Error(), output)
+	/* nothing left to write if every entry was rejected */
+	if failed == len(entries) {
+		return change, nil
 	}
 
+	if err := facl.Finalize(); err != nil {
+		return nil, fmt.Errorf("invalid ACL: %w", err)
+	}
+
+	if err := facl.Write(absolutePath); err != nil {
+		return nil, fmt.Errorf("failed to write ACL: %w", err)
+	}
+
+	change.written = true
+	change.after = facl.Rules()
+	change.warnings = facl.MaskWarnings(applied)
+
+	return change, nil
+}
+
+/* handles local restoreacl transaction (write back a captured ACL image via mounts) */
+func (p *PermProcessor) HandleLocalRestoreACL(txn *types.Transaction, absolutePath string) error {
+	/* restore must not interleave with other changes of the same path */
+	lock := getPathLock(absolutePath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	start := time.Now()
+	defer func() {
+		txn.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	/* status of transaction is successful (it was processed), execution depends on restore */
 	txn.Status = types.StatusSuccess
+	txn.ExecStatus = false
+
+	facl, err := posixacl.Read(absolutePath)
+	if err != nil {
+		txn.ErrorMsg = fmt.Sprintf("failed to read ACL: %s", err.Error())
+		return nil
+	}
+
+	/* without an expected ACL the target is overwritten unconditionally (copyacl) */
+	before := facl.Rules()
+	if len(txn.ExpectedACL) != 0 && !posixacl.EqualRules(before, txn.ExpectedACL) {
+		txn.ErrorMsg = "ACL of target path has changed since the reverted transaction"
+		return nil
+	}
+
+	/* a copy from a directory onto a file keeps only the access ACL, files can't hold default entries */
+	rules := txn.ACL
+	if !facl.IsDir {
+		rules = accessRules(rules)
+	}
+
+	if err := facl.Restore(rules); err != nil {
+		txn.ErrorMsg = fmt.Sprintf("failed to restore ACL: %s", err.Error())
+		return nil
+	}
+
+	if err := facl.Write(absolutePath); err != nil {
+		txn.ErrorMsg = fmt.Sprintf("failed to write ACL: %s", err.Error())
+		return nil
+	}
+
+	txn.BeforeACL = before
+	txn.ACL = facl.Rules()
 	txn.ExecStatus = true
+	txn.Output = "ACL restored"
 
 	return nil
 }
 
-/* builds the ACL entry string for setfacl */
-func BuildACLEntry(entry types.ACLEntry) string {
-	var sb strings.Builder
+/* handles local getfacl transaction (read access and default ACL via mounts) */
+func (p *PermProcessor) HandleLocalGetACL(txn *types.Transaction, absolutePath string) error {
+	start := time.Now()
+
+	rules, err := ReadLocalACL(absolutePath)
+
+	txn.DurationMs = time.Since(start).Milliseconds()
 
-	if entry.IsDefault {
-		sb.WriteString("default:")
+	/* status of transaction is successful (it was processed), execution depends on getfacl */
+	txn.Status = types.StatusSuccess
+	if err != nil {
+		txn.ExecStatus = false
+		txn.ErrorMsg = err.Error()
+		return nil
 	}
 
-	sb.WriteString(entry.EntityType)
-	sb.WriteString(":")
-	sb.WriteString(entry.Entity)
-	sb.WriteString(":")
-	sb.WriteString(entry.Permissions)
+	txn.ACL = rules
+	txn.ExecStatus = true
+
+	return nil
+}
+
+/* reads access and default ACL of a local path from its xattrs */
+func ReadLocalACL(absolutePath string) ([]types.ACLRule, error) {
+	facl, err := posixacl.Read(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL: %w", err)
+	}
 
-	return sb.String()
+	return facl.Rules(), nil
 }
@@ -1,6 +1,10 @@
 package transprocessor
 
-import "github.com/PythonHacker24/linux-acl-management-backend/internal/grpcpool"
+import (
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/grpcpool"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
 
 /*
 	transprocessor implements the transactions structure that whole project complies with
@@ -9,5 +13,33 @@ import "github.com/PythonHacker24/linux-acl-management-backend/internal/grpcpool
 /* permissions processor */
 type PermProcessor struct {
 	gRPCPool *grpcpool.ClientPool
+	retry    RetryPolicy
 	errCh    chan<- error
 }
+
+/* outcome of a transaction computed in memory, nothing is scheduled or written */
+type PreviewResponse struct {
+	TargetPath string `json:"targetPath"`
+
+	/* ACL on the target path right now */
+	Current []types.ACLRule `json:"current"`
+
+	/* ACL the target path would hold after the transaction */
+	Resulting []types.ACLRule `json:"resulting"`
+
+	/* recomputed masks of the resulting access and default ACL (empty if there is none) */
+	Mask        string `json:"mask,omitempty"`
+	DefaultMask string `json:"defaultMask,omitempty"`
+
+	/* what the owning group and each named entity can actually do under the mask */
+	Effective []posixacl.EffectiveRight `json:"effective"`
+
+	/* entries whose effective permissions would be narrower than requested */
+	Warnings []string `json:"warnings,omitempty"`
+
+	/* requested entries with success/error set as they would be executed */
+	Entries []types.ACLEntry `json:"entries"`
+
+	/* recursive transactions are only previewed on the target path itself */
+	Recursive bool `json:"recursive"`
+}
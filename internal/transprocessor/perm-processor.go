@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/grpcpool"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/session"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
@@ -15,6 +17,7 @@ import (
 func NewPermProcessor(gRPCPool *grpcpool.ClientPool, errCh chan<- error) *PermProcessor {
 	return &PermProcessor{
 		gRPCPool: gRPCPool,
+		retry:    NewRetryPolicy(config.BackendConfig.Retry),
 		errCh:    errCh,
 	}
 }
@@ -53,25 +56,74 @@ func (p *PermProcessor) Process(ctx context.Context, curSession *session.Session
 			zap.String("absolutePath", absolutePath),
 		)
 
-		if !found {
+		/* every execution counts as an attempt, retryable failures come back with Attempts > 1 */
+		txn.Attempts++
+
+		var err error
+		if txn.Operation == types.OperationChangeset {
+			/* every step is resolved on its own, steps may live on different servers */
+			err = p.HandleChangeset(ctx, txn)
+		} else if !found {
 			/* filepath is invalid, filesystem doesn't exist */
 			txn.ErrorMsg = "filesystem of given path doesn't exist"
 		} else {
-			if isRemote {
-				/* handle through daemons */
-				if err := p.HandleRemoteTransaction(host, port, txn, absolutePath); err != nil {
-					p.errCh <- err
-					return fmt.Errorf("failed to handle remote transaction")
+			/* operation decides between reading (getfacl) and writing (setfacl) ACLs */
+			switch txn.Operation {
+			case types.OperationGetACL:
+				if isRemote {
+					/* read through daemons */
+					err = p.HandleRemoteGetACL(ctx, host, port, txn, absolutePath)
+				} else {
+					/* read locally */
+					err = p.HandleLocalGetACL(txn, absolutePath)
+				}
+			case types.OperationSetACL:
+				if isRemote {
+					/* handle through daemons */
+					err = p.HandleRemoteTransaction(ctx, host, port, txn, absolutePath)
+				} else {
+					/* handle locally */
+					err = p.HandleLocalTransaction(ctx, txn, absolutePath)
 				}
-			} else {
-				/* handle locally */
-				if err := p.HandleLocalTransaction(txn, absolutePath); err != nil {
-					p.errCh <- err
-					return fmt.Errorf("failed to handler local transaction")
+			case types.OperationRestoreACL:
+				if isRemote {
+					/* restore through daemons */
+					err = p.HandleRemoteRestoreACL(ctx, host, port, txn, absolutePath)
+				} else {
+					/* restore locally */
+					err = p.HandleLocalRestoreACL(txn, absolutePath)
 				}
+			case types.OperationCopyACL:
+				/* source is resolved separately, it may live on another server */
+				err = p.HandleCopyACL(ctx, isRemote, host, port, txn, absolutePath)
+			default:
+				/* unknown operations are never executed */
+				txn.ErrorMsg = fmt.Sprintf("unsupported operation: %s", txn.Operation)
 			}
 		}
 
+		/* transient daemon failures count towards its circuit breaker, changeset steps are counted by the changeset */
+		if isRemote && found && txn.Operation != types.OperationChangeset {
+			p.recordOutcome(host, port, txn.ID, err)
+		}
+
+		if err != nil {
+			p.errCh <- err
+			txn.LastError = err.Error()
+
+			/* transient failures are requeued by the scheduler until attempts run out */
+			if p.retry.ShouldRetry(err, txn.Attempts) {
+				return &RetryableError{
+					Err:     err,
+					Backoff: p.retry.Backoff(txn.Attempts),
+				}
+			}
+
+			txn.ExecStatus = false
+			txn.ErrorMsg = err.Error()
+			return fmt.Errorf("failed to handle %s transaction after %d attempts: %w", txn.Operation, txn.Attempts, err)
+		}
+
 		/* REMOVE THIS */
 		zap.L().Info("Completed Transaction",
 			zap.String("ID", txn.ID.String()),
@@ -80,3 +132,20 @@ func (p *PermProcessor) Process(ctx context.Context, curSession *session.Session
 
 	return nil
 }
+
+/*
+counts the outcome of a request of a transaction towards the circuit breaker of the daemon
+other failures don't tell anything about the daemon, a half-open trial ending with one is given up when the transaction finishes
+*/
+func (p *PermProcessor) recordOutcome(host string, port int, txnID uuid.UUID, err error) {
+	if p.gRPCPool == nil {
+		return
+	}
+
+	address := fmt.Sprintf("%s:%d", host, port)
+	if IsRetryable(err) {
+		p.gRPCPool.RecordFailure(address, txnID, p.errCh)
+	} else if err == nil {
+		p.gRPCPool.RecordSuccess(address, txnID)
+	}
+}
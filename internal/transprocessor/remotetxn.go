@@ -2,21 +2,31 @@ package transprocessor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
 	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
 	protos "github.com/PythonHacker24/linux-acl-management-backend/proto"
 )
 
-/* NEED TO ADD DURATION TIME -> EXISTS IN LOCAL */
-
 /* takes a transactions and attempts to execute it via daemons */
-func (p *PermProcessor) HandleRemoteTransaction(host string, port int, txn *types.Transaction, absolutePath string) error {
+func (p *PermProcessor) HandleRemoteTransaction(ctx context.Context, host string, port int, txn *types.Transaction, absolutePath string) error {
+	_, err := p.applyRemoteEntries(ctx, host, port, txn, absolutePath, false)
+	return err
+}
+
+/*
+sends the entries of a transaction to the daemon serving the path
+returns the ACL captured before the change (nil for recursive transactions), also when the request failed
+with requireBefore, nothing is sent if the ACL can't be captured first
+*/
+func (p *PermProcessor) applyRemoteEntries(ctx context.Context, host string, port int, txn *types.Transaction, absolutePath string, requireBefore bool) ([]types.ACLRule, error) {
 
 	/* if gRPCPool is nil, return an error */
 	if p.gRPCPool == nil {
-		return fmt.Errorf("gRPC pool is nil")
+		return nil, fmt.Errorf("gRPC pool is nil")
 	}
 
 	/* get connection to the respective daemon */
@@ -24,37 +34,132 @@ func (p *PermProcessor) HandleRemoteTransaction(host string, port int, txn *type
 	conn, err := p.gRPCPool.GetConn(address, p.errCh)
 	if err != nil {
 		p.errCh <- err
-		return fmt.Errorf("failed to connect with a daemon: %s", address)
+		return nil, fmt.Errorf("%w: %s", ErrDaemonUnreachable, address)
 	}
 
-	/* make it a for loop for interating all entries */
-	aclpayload := &protos.ACLEntry{
-		EntityType:  txn.Entries.EntityType,
-		Entity:      txn.Entries.Entity,
-		Permissions: txn.Entries.Permissions,
-		Action:      txn.Entries.Action,
-		IsDefault:   txn.Entries.IsDefault,
+	/* all entries are sent in a single request so the daemon applies them together */
+	aclpayload := make([]*protos.ACLEntry, 0, len(txn.Entries))
+	for _, entry := range txn.Entries {
+		aclpayload = append(aclpayload, &protos.ACLEntry{
+			EntityType:      entry.EntityType,
+			Entity:          entry.Entity,
+			Permissions:     entry.Permissions,
+			Action:          entry.Action,
+			IsDefault:       entry.IsDefault,
+			MaskMode:        string(entry.MaskMode),
+			MaskPermissions: entry.MaskPermissions,
+		})
 	}
 
 	/* build the request for daemon */
 	request := &protos.ApplyACLRequest{
 		TransactionID: txn.ID.String(),
 		TargetPath:    absolutePath,
-		Entry:         aclpayload,
+		Entries:       aclpayload,
+		Recursive:     txn.Recursive,
+	}
+
+	/* older daemons only read the single entry field, it is filled whenever the request has one entry */
+	if len(aclpayload) == 1 {
+		request.Entry = aclpayload[0]
+	}
+
+	/*
+		capture the ACL before the change so the transaction can be reverted
+		recursive transactions touch too many paths to keep an image of each
+	*/
+	var before []types.ACLRule
+	if !txn.Recursive {
+		if before, err = p.ReadRemoteACL(ctx, host, port, txn.ID.String(), absolutePath); err != nil {
+			if requireBefore {
+				return nil, fmt.Errorf("failed to capture ACL before the change: %w", err)
+			}
+			p.errCh <- fmt.Errorf("failed to capture ACL before transaction %s: %w", txn.ID, err)
+		}
 	}
 
-	/* MAKE IT CONFIGURABLE */
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, p.retry.ApplyTimeout)
+	defer cancel()
+
+	start := time.Now()
 
 	aclClient := protos.NewACLServiceClient(conn)
 	aclResponse, err := aclClient.ApplyACLEntry(ctx, request)
-	if err != nil || aclResponse == nil {
-		p.errCh <- fmt.Errorf("failed to send ACL request to daemon")
-		cancel()
-		return err
+	if err != nil {
+		return before, fmt.Errorf("failed to apply ACL via daemon %s: %w", address, err)
+	}
+	if aclResponse == nil {
+		return before, fmt.Errorf("%w: %s", errEmptyResponse, address)
 	}
 
-	if aclResponse.Success {
+	txn.DurationMs = time.Since(start).Milliseconds()
+
+	/*
+		daemon reports a result for each entry in request order
+		if it doesn't, the overall result is applied to every entry
+	*/
+	failed := 0
+	for i := range txn.Entries {
+		entry := &txn.Entries[i]
+		if i < len(aclResponse.Results) {
+			entry.Success = aclResponse.Results[i].Success
+			entry.Error = ""
+			if !entry.Success {
+				entry.Error = aclResponse.Results[i].Message
+			}
+		} else {
+			entry.Success = aclResponse.Success
+			entry.Error = ""
+			if !entry.Success {
+				entry.Error = aclResponse.Message
+			}
+		}
+
+		if !entry.Success {
+			failed++
+		}
+	}
+
+	/* after image is only useful if there is a before image to pair it with */
+	if before != nil && failed < len(txn.Entries) {
+		after, err := p.ReadRemoteACL(ctx, host, port, txn.ID.String(), absolutePath)
+		if err != nil {
+			p.errCh <- fmt.Errorf("failed to capture ACL after transaction %s: %w", txn.ID, err)
+		} else {
+			txn.BeforeACL = before
+			txn.ACL = after
+		}
+	}
+
+	/* daemons which don't report mask warnings get them computed from the after image */
+	txn.Warnings = aclResponse.Warnings
+	if len(txn.Warnings) == 0 && len(txn.ACL) != 0 {
+		if facl, err := posixacl.FromRules(txn.ACL, false); err == nil {
+			applied := make([]types.ACLEntry, 0, len(txn.Entries))
+			for _, entry := range txn.Entries {
+				if entry.Success {
+					applied = append(applied, entry)
+				}
+			}
+			txn.Warnings = facl.MaskWarnings(applied)
+		}
+	}
+
+	/* paths the daemon couldn't change during a recursive walk */
+	for _, failure := range aclResponse.Failures {
+		if len(txn.Failures) >= maxRecordedFailures {
+			break
+		}
+		txn.Failures = append(txn.Failures, types.PathFailure{
+			Path:  failure.Path,
+			Error: failure.Message,
+		})
+	}
+
+	/* status of transaction is successful (it was processed), execution depends on entries */
+	txn.Status = types.StatusSuccess
+
+	if aclResponse.Success && failed == 0 && len(aclResponse.Failures) == 0 && len(txn.Entries) > 0 {
 
 		/*
 			this is a bit crude for now, let daemon set this
@@ -66,9 +171,221 @@ func (p *PermProcessor) HandleRemoteTransaction(host string, port int, txn *type
 
 		txn.ExecStatus = true
 	} else {
+		txn.ExecStatus = false
 		txn.ErrorMsg = "ACL failed to get executed in the filesystem server"
+		if failed > 0 {
+			txn.ErrorMsg = fmt.Sprintf("%s: %d of %d ACL entries failed", txn.ErrorMsg, failed, len(txn.Entries))
+		}
+		if len(aclResponse.Failures) > 0 {
+			txn.ErrorMsg = fmt.Sprintf("%s: %d paths failed", txn.ErrorMsg, len(aclResponse.Failures))
+		}
+	}
+
+	return before, nil
+}
+
+/* takes a restoreacl transaction and writes back the captured ACL via daemons */
+func (p *PermProcessor) HandleRemoteRestoreACL(ctx context.Context, host string, port int, txn *types.Transaction, absolutePath string) error {
+
+	/* if gRPCPool is nil, return an error */
+	if p.gRPCPool == nil {
+		return fmt.Errorf("gRPC pool is nil")
+	}
+
+	/* get connection to the respective daemon */
+	address := fmt.Sprintf("%s:%d", host, port)
+	conn, err := p.gRPCPool.GetConn(address, p.errCh)
+	if err != nil {
+		p.errCh <- err
+		return fmt.Errorf("%w: %s", ErrDaemonUnreachable, address)
+	}
+
+	/* without an expected ACL (copyacl) the before image has to be read first */
+	before := txn.ExpectedACL
+	isDir := true
+	if len(before) == 0 {
+		if current, err := p.getRemoteACL(ctx, host, port, txn.ID.String(), absolutePath); err != nil {
+			p.errCh <- fmt.Errorf("failed to capture ACL before transaction %s: %w", txn.ID, err)
+		} else {
+			before = rulesFromProto(current.Entries)
+			isDir = current.IsDir
+		}
+	}
+
+	/* a copy from a directory onto a file keeps only the access ACL, files can't hold default entries */
+	rules := txn.ACL
+	if !isDir {
+		rules = accessRules(rules)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.retry.RequestTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	/* daemon compares the current ACL with the expected one before writing */
+	aclClient := protos.NewACLServiceClient(conn)
+	aclResponse, err := aclClient.RestoreACL(ctx, &protos.RestoreACLRequest{
+		TransactionID: txn.ID.String(),
+		TargetPath:    absolutePath,
+		Acl:           rulesToProto(rules),
+		Expected:      rulesToProto(txn.ExpectedACL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore ACL via daemon %s: %w", address, err)
+	}
+	if aclResponse == nil {
+		return fmt.Errorf("%w: %s", errEmptyResponse, address)
+	}
+
+	txn.DurationMs = time.Since(start).Milliseconds()
+
+	/* status of transaction is successful (it was processed), execution depends on daemon */
+	txn.Status = types.StatusSuccess
+	txn.ExecStatus = aclResponse.Success
+
+	switch {
+	case aclResponse.Success:
+		txn.BeforeACL = before
+		txn.Output = "ACL restored"
+
+		/* the daemon drops default entries on files, so the written ACL may differ from the image */
+		if len(txn.ExpectedACL) == 0 {
+			if after, err := p.ReadRemoteACL(ctx, host, port, txn.ID.String(), absolutePath); err == nil {
+				txn.ACL = after
+			} else {
+				p.errCh <- fmt.Errorf("failed to capture ACL after transaction %s: %w", txn.ID, err)
+			}
+		}
+	case aclResponse.Conflict:
+		txn.ErrorMsg = "ACL of target path has changed since the reverted transaction"
+	default:
+		txn.ErrorMsg = fmt.Sprintf("ACL failed to get restored in the filesystem server: %s", aclResponse.Message)
 	}
 
-	cancel()
 	return nil
 }
+
+/* takes a getfacl transaction and reads the ACL via daemons */
+func (p *PermProcessor) HandleRemoteGetACL(ctx context.Context, host string, port int, txn *types.Transaction, absolutePath string) error {
+	start := time.Now()
+
+	rules, err := p.ReadRemoteACL(ctx, host, port, txn.ID.String(), absolutePath)
+
+	txn.DurationMs = time.Since(start).Milliseconds()
+
+	/* daemon couldn't be reached in time, let the processor decide about retrying */
+	if IsRetryable(err) {
+		return err
+	}
+
+	/* status of transaction is successful (it was processed), execution depends on daemon */
+	txn.Status = types.StatusSuccess
+	if err != nil {
+		txn.ExecStatus = false
+		txn.ErrorMsg = err.Error()
+		return nil
+	}
+
+	txn.ACL = rules
+	txn.ExecStatus = true
+
+	return nil
+}
+
+/* reads access and default ACL of a path on a filesystem server via its daemon */
+func (p *PermProcessor) ReadRemoteACL(ctx context.Context, host string, port int, txnID string, absolutePath string) ([]types.ACLRule, error) {
+	aclResponse, err := p.getRemoteACL(ctx, host, port, txnID, absolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return rulesFromProto(aclResponse.Entries), nil
+}
+
+/* reads the ACL of a path on a filesystem server via its daemon for in memory processing */
+func (p *PermProcessor) ReadRemoteFileACL(ctx context.Context, host string, port int, txnID string, absolutePath string) (*posixacl.FileACL, error) {
+	aclResponse, err := p.getRemoteACL(ctx, host, port, txnID, absolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	facl, err := posixacl.FromRules(rulesFromProto(aclResponse.Entries), aclResponse.IsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ACL from daemon: %w", err)
+	}
+
+	return facl, nil
+}
+
+/* daemon was reached but couldn't read the ACL of the path */
+var errRemoteRead = errors.New("daemon failed to read ACL")
+
+/* daemon answered a request without a response */
+var errEmptyResponse = errors.New("daemon sent an empty response")
+
+/* sends a GetACL request to the daemon serving the path */
+func (p *PermProcessor) getRemoteACL(ctx context.Context, host string, port int, txnID string, absolutePath string) (*protos.GetACLResponse, error) {
+
+	/* if gRPCPool is nil, return an error */
+	if p.gRPCPool == nil {
+		return nil, fmt.Errorf("gRPC pool is nil")
+	}
+
+	/* get connection to the respective daemon */
+	address := fmt.Sprintf("%s:%d", host, port)
+	conn, err := p.gRPCPool.GetConn(address, p.errCh)
+	if err != nil {
+		p.errCh <- err
+		return nil, fmt.Errorf("%w: %s", ErrDaemonUnreachable, address)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.retry.RequestTimeout)
+	defer cancel()
+
+	aclClient := protos.NewACLServiceClient(conn)
+	aclResponse, err := aclClient.GetACL(ctx, &protos.GetACLRequest{
+		TransactionID: txnID,
+		TargetPath:    absolutePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL from daemon %s: %w", address, err)
+	}
+	if aclResponse == nil {
+		return nil, fmt.Errorf("%w: %s", errEmptyResponse, address)
+	}
+
+	if !aclResponse.Success {
+		return nil, fmt.Errorf("%w: %s", errRemoteRead, aclResponse.Message)
+	}
+
+	return aclResponse, nil
+}
+
+/* converts ACL rules received from a daemon */
+func rulesFromProto(entries []*protos.ACLRule) []types.ACLRule {
+	rules := make([]types.ACLRule, 0, len(entries))
+	for _, entry := range entries {
+		rules = append(rules, types.ACLRule{
+			EntityType:  entry.EntityType,
+			Entity:      entry.Entity,
+			Permissions: entry.Permissions,
+			IsDefault:   entry.IsDefault,
+		})
+	}
+	return rules
+}
+
+/* converts ACL rules to be sent to a daemon */
+func rulesToProto(rules []types.ACLRule) []*protos.ACLRule {
+	entries := make([]*protos.ACLRule, 0, len(rules))
+	for _, rule := range rules {
+		entries = append(entries, &protos.ACLRule{
+			EntityType:  rule.EntityType,
+			Entity:      rule.Entity,
+			Permissions: rule.Permissions,
+			IsDefault:   rule.IsDefault,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,97 @@
+package transprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+)
+
+/* daemon serving the path couldn't be reached, the transaction may succeed later */
+var ErrDaemonUnreachable = errors.New("failed to connect with a daemon")
+
+/*
+returned by Process when a transaction failed with a transient error and should be requeued
+the scheduler puts the transaction back in the session queue after Backoff
+*/
+type RetryableError struct {
+	Err     error
+	Backoff time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	return fmt.Sprintf("transient failure, retrying in %s: %s", e.Backoff, e.Err)
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+/*
+decides if and when failed transactions are attempted again
+requests to daemons are bounded by ApplyTimeout (ACL changes) and RequestTimeout (reads and restores)
+*/
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	ApplyTimeout   time.Duration
+	RequestTimeout time.Duration
+}
+
+/* builds the retry policy from the retry section of the configuration */
+func NewRetryPolicy(cfg config.Retry) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.InitialBackoff) * time.Second,
+		MaxBackoff:     time.Duration(cfg.MaxBackoff) * time.Second,
+		Multiplier:     cfg.Multiplier,
+		ApplyTimeout:   time.Duration(cfg.ApplyTimeout) * time.Second,
+		RequestTimeout: time.Duration(cfg.RequestTimeout) * time.Second,
+	}
+}
+
+/* exponential backoff before the next attempt, attempts counts the attempts made so far */
+func (r RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := float64(r.InitialBackoff) * math.Pow(r.Multiplier, float64(attempts-1))
+	if backoff > float64(r.MaxBackoff) {
+		return r.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+/* whether a transaction that made attempts so far and failed with err should be attempted again */
+func (r RetryPolicy) ShouldRetry(err error, attempts int) bool {
+	return IsRetryable(err) && attempts < r.MaxAttempts
+}
+
+/*
+classifies errors as retryable (transient) or permanent
+daemons that are down, overloaded or too slow are transient, everything else is permanent
+*/
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrDaemonUnreachable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	/* gRPC status may be wrapped, status.FromError unwraps it */
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,10 +1,16 @@
 package transprocessor
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"path"
 	"strings"
 
 	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/validation"
 )
 
 func FindServerFromPath(filepath string) (isRemote bool, host string, port int, found bool, absolutePath string) {
@@ -25,3 +31,34 @@ func FindServerFromPath(filepath string) (isRemote bool, host string, port int,
 	/* filesystem not found */
 	return false, "", 0, false, ""
 }
+
+/*
+checks that a target path lies under a configured filesystem server and exists there
+implements validation.PathChecker
+*/
+func (p *PermProcessor) CheckPath(targetPath string) error {
+	isRemote, host, port, found, absolutePath := FindServerFromPath(targetPath)
+	if !found {
+		return fmt.Errorf("%w: not under any configured filesystem server", validation.ErrPathNotFound)
+	}
+
+	if isRemote {
+		/* daemons report a missing path as a failed read, validation isn't bound to a transaction */
+		if _, err := p.getRemoteACL(context.Background(), host, port, "", absolutePath); err != nil {
+			if errors.Is(err, errRemoteRead) {
+				return fmt.Errorf("%w: %w", validation.ErrPathNotFound, err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(absolutePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return validation.ErrPathNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -3,13 +3,13 @@ package traversal
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/posixacl"
 )
 
 /* comprehensive list of dangerous characters */
@@ -98,7 +98,8 @@ func ListFiles(path string, userID string) ([]FileEntry, error) {
 }
 
 /*
-checks if the user is the owner of the file using getfacl
+checks if the user is the owner of the file (or has write access through a named ACL entry)
+the ACL is read straight from the xattrs, no getfacl process is spawned per file
 */
 func isOwner(filePath string, userCN string) (bool, error) {
 	cleanPath := filepath.Clean(filePath)
@@ -114,38 +115,28 @@ func isOwner(filePath string, userCN string) (bool, error) {
 		}
 	}
 
-	/* get the file's ACL using getfacl with the file path directly */
-	cmd := exec.Command("getfacl", cleanPath)
-	output, err := cmd.Output()
+	/* get the file's owner and ACL */
+	facl, err := posixacl.Read(cleanPath)
 	if err != nil {
-		zap.L().Error("Failed to execute getfacl",
+		zap.L().Error("Failed to read ACL",
 			zap.String("path", cleanPath),
 			zap.Error(err),
 		)
 		return false, fmt.Errorf("failed to check file permissions: %w", err)
 	}
 
-	/* parse the getfacl output to check ownership */
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	/* check ownership */
+	if strings.EqualFold(posixacl.UserName(facl.UID), userCN) {
+		return true, nil
+	}
 
-		if strings.HasPrefix(line, "# owner:") {
-			owner := strings.TrimSpace(strings.TrimPrefix(line, "# owner:"))
-			if strings.EqualFold(owner, userCN) {
-				return true, nil
-			}
+	/* check named user entries with write permission */
+	for _, entry := range facl.Access {
+		if entry.Tag != posixacl.TagUser || entry.Perm&posixacl.PermWrite == 0 {
+			continue
 		}
-
-		if strings.HasPrefix(line, "user:") && !strings.HasPrefix(line, "user::") {
-			parts := strings.Split(line, ":")
-			if len(parts) >= 3 {
-				aclUser := parts[1]
-				permissions := parts[2]
-				if strings.EqualFold(aclUser, userCN) && strings.Contains(permissions, "w") {
-					return true, nil
-				}
-			}
+		if strings.EqualFold(posixacl.UserName(entry.ID), userCN) {
+			return true, nil
 		}
 	}
 
@@ -16,7 +16,37 @@ import (
 type ScheduleTransactionRequest struct {
 	Operation  OperationType `json:"operation"`
 	TargetPath string        `json:"targetPath"`
-	Entries    ACLEntry      `json:"entries"`
+	Entries    []ACLEntry    `json:"entries"`
+
+	/* apply entries to every file under TargetPath (setfacl -R) */
+	Recursive bool `json:"recursive"`
+
+	/* copyacl: path to copy the ACL from */
+	SourcePath string `json:"sourcePath,omitempty"`
+
+	/* copyacl: paths to copy the ACL to, one transaction is scheduled per target */
+	TargetPaths []string `json:"targetPaths,omitempty"`
+
+	/* transaction is held back until this time instead of being queued right away */
+	ExecuteAt *time.Time `json:"executeAt,omitempty"`
+
+	/* scheduler lane, normal if empty */
+	Priority Priority `json:"priority,omitempty"`
+}
+
+/* request body for scheduling a changeset */
+type ScheduleChangesetRequest struct {
+	/* applied in order, all or nothing */
+	Steps []ChangesetStepRequest `json:"steps"`
+
+	/* scheduler lane, normal if empty */
+	Priority Priority `json:"priority,omitempty"`
+}
+
+/* a single path of a changeset and the entries applied to it */
+type ChangesetStepRequest struct {
+	TargetPath string     `json:"targetPath"`
+	Entries    []ACLEntry `json:"entries"`
 }
 
 /* represents the result of the transaction */
@@ -27,6 +57,9 @@ const (
 	StatusPending TxnStatus = "pending"
 	StatusSuccess TxnStatus = "success"
 	StatusFailed  TxnStatus = "failed"
+
+	/* removed from the queue by the user before a worker picked it up */
+	StatusCancelled TxnStatus = "cancelled"
 )
 
 /* represents what kind of ACL operation was performed */
@@ -36,6 +69,60 @@ type OperationType string
 const (
 	OperationGetACL OperationType = "getfacl"
 	OperationSetACL OperationType = "setfacl"
+
+	/* replaces the whole ACL with a captured image (used to revert transactions) */
+	OperationRestoreACL OperationType = "restoreacl"
+
+	/* replaces the whole ACL with the ACL of SourcePath */
+	OperationCopyACL OperationType = "copyacl"
+
+	/* applies entries to several paths as one change, applied steps are rolled back if any step fails */
+	OperationChangeset OperationType = "changeset"
+)
+
+/* lane of a transaction in the scheduler, higher lanes are drained first */
+type Priority string
+
+/* defining priority lanes, lowest first */
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+
+	/* restricted to the roles configured in priority.high_roles */
+	PriorityHigh Priority = "high"
+
+	/* restricted to the roles configured in priority.urgent_roles (emergency revocations) */
+	PriorityUrgent Priority = "urgent"
+)
+
+/* represents the outcome of a single changeset step */
+type StepStatus string
+
+/* defining changeset step status types */
+const (
+	StepPending        StepStatus = "pending"
+	StepApplied        StepStatus = "applied"
+	StepFailed         StepStatus = "failed"
+	StepRolledBack     StepStatus = "rolledback"
+	StepRollbackFailed StepStatus = "rollbackfailed"
+
+	/* never executed because an earlier step failed */
+	StepSkipped StepStatus = "skipped"
+)
+
+/* decides what happens to the mask after an entry is applied */
+type MaskMode string
+
+/* defining mask modes */
+const (
+	/* mask becomes the union of the owning group and named entries (setfacl default) */
+	MaskRecalculate MaskMode = "recalculate"
+
+	/* existing mask is kept as it is (setfacl -n) */
+	MaskPreserve MaskMode = "preserve"
+
+	/* mask is set to MaskPermissions */
+	MaskSet MaskMode = "set"
 )
 
 /* represents an individual ACL rule attempted to be changed */
@@ -49,18 +136,74 @@ type ACLEntry struct {
 	*/
 	Entity string `json:"entity"`
 
-	/* e.g., "rwx", "rw-", etc. */
+	/*
+		e.g., "rwx", "rw-", etc.
+		"X" grants execute only on directories and already executable files
+	*/
 	Permissions string `json:"permissions"`
 
-	/* e.g., "add", "modify", "remove" */
+	/*
+		e.g., "add", "modify", "remove"
+		"set" replaces the whole ACL (setfacl --set), all "set" entries together form the new ACL
+		"remove-all" (setfacl -b) and "remove-default" (setfacl -k) ignore entity and permissions
+	*/
 	Action string `json:"action"`
 
 	/* whether this is a default ACL (i.e., applies to new files/subdirs) */
 	IsDefault bool `json:"isDefault"`
 
+	/* mask handling of the ACL this entry belongs to, recalculate if empty */
+	MaskMode MaskMode `json:"maskMode,omitempty"`
+
+	/* mask permissions, only used with MaskSet */
+	MaskPermissions string `json:"maskPermissions,omitempty"`
+
+	/* time-bound grant; the entry is removed again at this time (add/modify of named entries only) */
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
 	/* only set if failed */
 	Error   string `json:"error,omitempty"`
 	Success bool   `json:"success"`
+
+	/* recursive transactions: paths the entry was applied to, it may have failed on the others */
+	AppliedPaths int `json:"appliedPaths,omitempty"`
+}
+
+/* represents an ACL rule currently present on a file or directory */
+type ACLRule struct {
+	/* e.g., "user", "group", "mask", "other" */
+	EntityType string `json:"entityType"`
+
+	/* username, group name, or blank for owner/owning group/mask/other */
+	Entity string `json:"entity"`
+
+	/* e.g., "rwx", "r-x", etc. */
+	Permissions string `json:"permissions"`
+
+	/* whether this rule belongs to the default ACL */
+	IsDefault bool `json:"isDefault"`
+}
+
+/* represents a path that could not be changed during a recursive transaction */
+type PathFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+/* represents a single path of a changeset */
+type ChangesetStep struct {
+	TargetPath string     `json:"targetPath"`
+	Entries    []ACLEntry `json:"entries"`
+
+	Status   StepStatus `json:"status"`
+	ErrorMsg string     `json:"errorMsg,omitempty"`
+
+	/* entries whose effective permissions are narrower than requested due to the mask */
+	Warnings []string `json:"warnings,omitempty"`
+
+	/* ACL of the path before and after the step, the before-image is restored on rollback */
+	BeforeACL []ACLRule `json:"beforeAcl,omitempty"`
+	ACL       []ACLRule `json:"acl,omitempty"`
 }
 
 /* holds the full state of a permission change operation */
@@ -75,8 +218,23 @@ type Transaction struct {
 	/* File/directory affected */
 	TargetPath string `json:"targetPath"`
 
-	/* ACL entries involved */
-	Entries ACLEntry `json:"entries"`
+	/* copyacl: File/directory the ACL is copied from */
+	SourcePath string `json:"sourcePath,omitempty"`
+
+	/* ACL entries involved (applied together on the target path) */
+	Entries []ACLEntry `json:"entries"`
+
+	/* changeset: paths changed as one unit, TargetPath is the path of the first step */
+	Steps []ChangesetStep `json:"steps,omitempty"`
+
+	/* entries are applied to the whole subtree under TargetPath */
+	Recursive bool `json:"recursive"`
+
+	/* deferred transactions are dispatched to the scheduler at this time */
+	ExecuteAt *time.Time `json:"executeAt,omitempty"`
+
+	/* scheduler lane, higher lanes are drained first */
+	Priority Priority `json:"priority"`
 
 	/* success/failure/pending */
 	Status TxnStatus `json:"status"`
@@ -87,9 +245,42 @@ type Transaction struct {
 	/* set if failed */
 	ErrorMsg string `json:"errorMsg,omitempty"`
 
+	/* number of times execution was attempted, retryable failures are requeued */
+	Attempts int `json:"attempts"`
+
+	/* error of the latest failed attempt */
+	LastError string `json:"lastError,omitempty"`
+
 	/* stdout or stderr captured */
 	Output string `json:"output"`
 
+	/* entries whose effective permissions are narrower than requested due to the mask */
+	Warnings []string `json:"warnings,omitempty"`
+
+	/* paths that failed during a recursive transaction */
+	Failures []PathFailure `json:"failures,omitempty"`
+
+	/*
+		access and default ACL of the target path
+		getfacl: as read, setfacl: after the change, restoreacl: the image to restore
+	*/
+	ACL []ACLRule `json:"acl,omitempty"`
+
+	/* access and default ACL of the target path before the change (used for reverting) */
+	BeforeACL []ACLRule `json:"beforeAcl,omitempty"`
+
+	/* restoreacl only runs if the target path still holds exactly this ACL */
+	ExpectedACL []ACLRule `json:"expectedAcl,omitempty"`
+
+	/* transaction reverted by this one */
+	RevertOf *uuid.UUID `json:"revertOf,omitempty"`
+
+	/* recovered from the pending archive, it is removed from there once processed */
+	Replayed bool `json:"replayed,omitempty"`
+
+	/* result is stored in the results archive */
+	Archived bool `json:"archived,omitempty"`
+
 	/* user who triggered this */
 	ExecutedBy string `json:"executedBy"`
 
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+	validation checks transaction requests before they are queued
+	anything that can be rejected upfront is reported back per field
+	instead of surfacing later as a failed transaction
+*/
+
+/* reported by a PathChecker when the target path doesn't exist */
+var ErrPathNotFound = errors.New("path not found")
+
+/* checks that a target path exists on the filesystem server serving it */
+type PathChecker interface {
+	CheckPath(targetPath string) error
+}
+
+/* resolves ACL entities (and the roles of users) against the user directory */
+type EntityResolver interface {
+	UserExists(name string) (bool, error)
+	GroupExists(name string) (bool, error)
+	UserGroups(username string) ([]string, error)
+}
+
+/* validator for transaction requests */
+type Validator struct {
+	paths    PathChecker
+	entities EntityResolver
+}
+
+/* a single invalid field of a request */
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+/* all invalid fields of a request */
+type FieldErrors []FieldError
+
+/* implements error so field errors can be returned as one */
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+/* a path of the request and the field it came from */
+type pathField struct {
+	field string
+	path  string
+}
@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/config"
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* reported when a user isn't in any role allowed to use the requested priority */
+var ErrPriorityNotAllowed = errors.New("priority not allowed")
+
+/* reports if a priority is one of the known lanes, empty means normal */
+func validPriority(priority types.Priority) bool {
+	switch priority {
+	case "", types.PriorityLow, types.PriorityNormal, types.PriorityHigh, types.PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+/*
+checks that a user may schedule transactions with a priority
+high is open to high and urgent roles, urgent to urgent roles only, the roles are LDAP groups
+*/
+func (v *Validator) AuthorizePriority(username string, priority types.Priority) error {
+	var roles []string
+	switch priority {
+	case types.PriorityHigh:
+		roles = append(roles, config.BackendConfig.Priority.HighRoles...)
+		roles = append(roles, config.BackendConfig.Priority.UrgentRoles...)
+	case types.PriorityUrgent:
+		roles = config.BackendConfig.Priority.UrgentRoles
+	default:
+		return nil
+	}
+
+	if len(roles) == 0 {
+		return ErrPriorityNotAllowed
+	}
+
+	groups, err := v.entities.UserGroups(username)
+	if err != nil {
+		return fmt.Errorf("failed to get groups of %s: %w", username, err)
+	}
+
+	for _, group := range groups {
+		if slices.Contains(roles, group) {
+			return nil
+		}
+	}
+
+	return ErrPriorityNotAllowed
+}
@@ -0,0 +1,300 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+/* permissions are always three characters, "X" is setfacl's conditional execute */
+var permissionsPattern = regexp.MustCompile(`^[r-][w-][xX-]$`)
+
+/* upper bound on the number of paths changed by one changeset */
+const maxChangesetSteps = 100
+
+/* create a new validator */
+func NewValidator(paths PathChecker, entities EntityResolver) *Validator {
+	return &Validator{
+		paths:    paths,
+		entities: entities,
+	}
+}
+
+/*
+validates a transaction request
+returns FieldErrors if the request is invalid and a plain error if it couldn't be validated
+*/
+func (v *Validator) ValidateRequest(req *types.ScheduleTransactionRequest) error {
+	var fieldErrs FieldErrors
+	add := func(field, format string, args ...any) {
+		fieldErrs = append(fieldErrs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	/* operation; restoreacl is only scheduled by the backend itself */
+	switch req.Operation {
+	case types.OperationGetACL:
+		if len(req.Entries) != 0 {
+			add("entries", "must be empty for %s", req.Operation)
+		}
+		if req.Recursive {
+			add("recursive", "is not supported for %s", req.Operation)
+		}
+	case types.OperationSetACL:
+		if len(req.Entries) == 0 {
+			add("entries", "at least one entry is required for %s", req.Operation)
+		}
+	case types.OperationCopyACL:
+		if len(req.Entries) != 0 {
+			add("entries", "must be empty for %s", req.Operation)
+		}
+		if req.Recursive {
+			add("recursive", "is not supported for %s", req.Operation)
+		}
+	default:
+		add("operation", "must be one of %q, %q, %q", types.OperationGetACL, types.OperationSetACL, types.OperationCopyACL)
+	}
+
+	if !validPriority(req.Priority) {
+		add("priority", "must be one of %q, %q, %q, %q", types.PriorityLow, types.PriorityNormal, types.PriorityHigh, types.PriorityUrgent)
+	}
+
+	/* paths must be clean absolute paths, the valid ones are checked on the filesystem servers last */
+	var paths []pathField
+	checkSyntax := func(field, p string) {
+		switch {
+		case p == "":
+			add(field, "is required")
+		case !strings.HasPrefix(p, "/"):
+			add(field, "must be an absolute path")
+		case path.Clean(p) != p:
+			add(field, "must not contain '.', '..' or repeated separators")
+		default:
+			paths = append(paths, pathField{field: field, path: p})
+		}
+	}
+
+	if req.Operation == types.OperationCopyACL {
+		checkSyntax("sourcePath", req.SourcePath)
+		if len(req.TargetPaths) == 0 {
+			checkSyntax("targetPath", req.TargetPath)
+		}
+		for i, target := range req.TargetPaths {
+			checkSyntax(fmt.Sprintf("targetPaths[%d]", i), target)
+		}
+	} else {
+		checkSyntax("targetPath", req.TargetPath)
+		if req.SourcePath != "" || len(req.TargetPaths) != 0 {
+			add("sourcePath", "sourcePath and targetPaths are only supported for %s", types.OperationCopyACL)
+		}
+	}
+
+	/* deferred transactions must be due in the future */
+	if req.ExecuteAt != nil && !req.ExecuteAt.After(time.Now()) {
+		add("executeAt", "must be in the future")
+	}
+
+	/* entries */
+	setAccess := make(map[string]bool)
+	setDefault := make(map[string]bool)
+	for i, entry := range req.Entries {
+		field := fmt.Sprintf("entries[%d]", i)
+
+		/* these strip whole ACLs and don't refer to an entity */
+		if entry.Action == "remove-all" || entry.Action == "remove-default" {
+			if entry.EntityType != "" || entry.Entity != "" || entry.Permissions != "" {
+				add(field, "entityType, entity and permissions must be empty for %s", entry.Action)
+			}
+			if entry.MaskMode != "" || entry.MaskPermissions != "" {
+				add(field+".maskMode", "is not supported for %s", entry.Action)
+			}
+			continue
+		}
+
+		/* remember which base entries "set" provides */
+		if entry.Action == "set" && entry.Entity == "" {
+			if entry.IsDefault {
+				setDefault[entry.EntityType] = true
+			} else {
+				setAccess[entry.EntityType] = true
+			}
+		}
+
+		switch entry.EntityType {
+		case "user", "group":
+			/* named entities are resolved below, blank means owner/owning group */
+		case "mask", "other":
+			if entry.Entity != "" {
+				add(field+".entity", "must be empty for %s entries", entry.EntityType)
+			}
+		default:
+			add(field+".entityType", "must be one of \"user\", \"group\", \"mask\", \"other\"")
+		}
+
+		switch entry.Action {
+		case "add", "modify", "set":
+			if !permissionsPattern.MatchString(entry.Permissions) {
+				add(field+".permissions", "must match [r-][w-][xX-], e.g. \"rwx\" or \"r-X\"")
+			}
+		case "remove":
+			if entry.Permissions != "" && !permissionsPattern.MatchString(entry.Permissions) {
+				add(field+".permissions", "must be empty or match [r-][w-][xX-]")
+			}
+			if entry.EntityType == "user" && entry.Entity == "" ||
+				entry.EntityType == "group" && entry.Entity == "" ||
+				entry.EntityType == "other" {
+				add(field+".entity", "base ACL entries cannot be removed")
+			}
+		default:
+			add(field+".action", "must be one of \"add\", \"modify\", \"remove\", \"set\", \"remove-all\", \"remove-default\"")
+		}
+
+		switch entry.MaskMode {
+		case "", types.MaskRecalculate, types.MaskPreserve:
+			if entry.MaskPermissions != "" {
+				add(field+".maskPermissions", "is only allowed with mask mode %q", types.MaskSet)
+			}
+		case types.MaskSet:
+			if !permissionsPattern.MatchString(entry.MaskPermissions) {
+				add(field+".maskPermissions", "must match [r-][w-][xX-]")
+			}
+		default:
+			add(field+".maskMode", "must be one of %q, %q, %q", types.MaskRecalculate, types.MaskPreserve, types.MaskSet)
+		}
+
+		/* time-bound grants are revoked with a "remove" of the same named entry */
+		if entry.ExpiresAt != nil {
+			switch {
+			case entry.Action != "add" && entry.Action != "modify":
+				add(field+".expiresAt", "is only supported for \"add\" and \"modify\"")
+			case entry.Entity == "" || (entry.EntityType != "user" && entry.EntityType != "group"):
+				add(field+".expiresAt", "is only supported for named user and group entries")
+			case !entry.ExpiresAt.After(time.Now()):
+				add(field+".expiresAt", "must be in the future")
+			case req.ExecuteAt != nil && !entry.ExpiresAt.After(*req.ExecuteAt):
+				add(field+".expiresAt", "must be after executeAt")
+			}
+		}
+	}
+
+	/* like setfacl --set, a replaced ACL must be complete */
+	if hasSetEntries(req.Entries, false) && !(setAccess["user"] && setAccess["group"] && setAccess["other"]) {
+		add("entries", "set requires user::, group:: and other:: entries for the access ACL")
+	}
+	if hasSetEntries(req.Entries, true) && !(setDefault["user"] && setDefault["group"] && setDefault["other"]) {
+		add("entries", "set requires user::, group:: and other:: entries for the default ACL")
+	}
+
+	/* named entities must exist in the directory, each one is looked up once */
+	resolved := make(map[string]bool)
+	for i, entry := range req.Entries {
+		if entry.Entity == "" || (entry.EntityType != "user" && entry.EntityType != "group") {
+			continue
+		}
+
+		key := entry.EntityType + ":" + entry.Entity
+		exists, ok := resolved[key]
+		if !ok {
+			var err error
+			if entry.EntityType == "user" {
+				exists, err = v.entities.UserExists(entry.Entity)
+			} else {
+				exists, err = v.entities.GroupExists(entry.Entity)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to look up %s %q: %w", entry.EntityType, entry.Entity, err)
+			}
+			resolved[key] = exists
+		}
+
+		if !exists {
+			add(fmt.Sprintf("entries[%d].entity", i), "%s %q doesn't exist", entry.EntityType, entry.Entity)
+		}
+	}
+
+	/* paths must exist under a configured filesystem server */
+	for _, p := range paths {
+		if err := v.paths.CheckPath(p.path); err != nil {
+			if !errors.Is(err, ErrPathNotFound) {
+				return fmt.Errorf("failed to check %s: %w", p.field, err)
+			}
+			add(p.field, "%s", err.Error())
+		}
+	}
+
+	if len(fieldErrs) != 0 {
+		return fieldErrs
+	}
+
+	return nil
+}
+
+/*
+validates a changeset request
+every step is validated like a setfacl transaction, field errors are reported under steps[i]
+*/
+func (v *Validator) ValidateChangeset(req *types.ScheduleChangesetRequest) error {
+	var fieldErrs FieldErrors
+	add := func(field, format string, args ...any) {
+		fieldErrs = append(fieldErrs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case len(req.Steps) == 0:
+		add("steps", "at least one step is required")
+	case len(req.Steps) > maxChangesetSteps:
+		add("steps", "must not contain more than %d steps", maxChangesetSteps)
+	}
+
+	if !validPriority(req.Priority) {
+		add("priority", "must be one of %q, %q, %q, %q", types.PriorityLow, types.PriorityNormal, types.PriorityHigh, types.PriorityUrgent)
+	}
+
+	for i, step := range req.Steps {
+		field := fmt.Sprintf("steps[%d]", i)
+
+		/* a rolled back step must not leave a grant behind for the reaper */
+		for j, entry := range step.Entries {
+			if entry.ExpiresAt != nil {
+				add(fmt.Sprintf("%s.entries[%d].expiresAt", field, j), "is not supported in changesets")
+			}
+		}
+
+		err := v.ValidateRequest(&types.ScheduleTransactionRequest{
+			Operation:  types.OperationSetACL,
+			TargetPath: step.TargetPath,
+			Entries:    step.Entries,
+		})
+
+		var stepErrs FieldErrors
+		if errors.As(err, &stepErrs) {
+			for _, stepErr := range stepErrs {
+				add(field+"."+stepErr.Field, "%s", stepErr.Message)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to validate %s: %w", field, err)
+		}
+	}
+
+	if len(fieldErrs) != 0 {
+		return fieldErrs
+	}
+
+	return nil
+}
+
+/* reports if any "set" entry targets the access or default ACL */
+func hasSetEntries(entries []types.ACLEntry, isDefault bool) bool {
+	for _, entry := range entries {
+		if entry.Action == "set" && entry.IsDefault == isDefault {
+			return true
+		}
+	}
+	return false
+}
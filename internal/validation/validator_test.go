@@ -0,0 +1,352 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PythonHacker24/linux-acl-management-backend/internal/types"
+)
+
+var errDirectoryDown = errors.New("directory unavailable")
+
+/* paths that exist on the filesystem servers, /srv/broken can't be checked */
+type stubPaths map[string]bool
+
+func (p stubPaths) CheckPath(targetPath string) error {
+	if targetPath == "/srv/broken" {
+		return errors.New("daemon unavailable")
+	}
+	if !p[targetPath] {
+		return fmt.Errorf("%s: %w", targetPath, ErrPathNotFound)
+	}
+	return nil
+}
+
+/* LDAP stand-in, lookups of "flaky" fail and every lookup is counted */
+type stubDirectory struct {
+	users   map[string]bool
+	groups  map[string]bool
+	lookups int
+}
+
+func (d *stubDirectory) UserExists(name string) (bool, error) {
+	d.lookups++
+	if name == "flaky" {
+		return false, errDirectoryDown
+	}
+	return d.users[name], nil
+}
+
+func (d *stubDirectory) GroupExists(name string) (bool, error) {
+	d.lookups++
+	if name == "flaky" {
+		return false, errDirectoryDown
+	}
+	return d.groups[name], nil
+}
+
+func (d *stubDirectory) UserGroups(username string) ([]string, error) {
+	return nil, nil
+}
+
+func newTestValidator() (*Validator, *stubDirectory) {
+	paths := stubPaths{"/srv/a": true, "/srv/b": true, "/srv/src": true}
+	directory := &stubDirectory{
+		users:  map[string]bool{"alice": true},
+		groups: map[string]bool{"staff": true},
+	}
+	return NewValidator(paths, directory), directory
+}
+
+/* setfacl request for a path */
+func setfacl(target string, entries ...types.ACLEntry) *types.ScheduleTransactionRequest {
+	return &types.ScheduleTransactionRequest{Operation: types.OperationSetACL, TargetPath: target, Entries: entries}
+}
+
+/* copyacl request from a source to targets, a single target goes to TargetPath */
+func copyacl(source string, targets ...string) *types.ScheduleTransactionRequest {
+	req := &types.ScheduleTransactionRequest{Operation: types.OperationCopyACL, SourcePath: source}
+	if len(targets) == 1 {
+		req.TargetPath = targets[0]
+	} else {
+		req.TargetPaths = targets
+	}
+	return req
+}
+
+func entry(entityType, entity, permissions, action string) types.ACLEntry {
+	return types.ACLEntry{EntityType: entityType, Entity: entity, Permissions: permissions, Action: action}
+}
+
+/* fields reported by a validation error, nil if valid */
+func errorFields(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("err = %v, want field errors", err)
+	}
+
+	fields := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		fields = append(fields, fieldErr.Field)
+	}
+	return fields
+}
+
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidatePaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *types.ScheduleTransactionRequest
+		wantFields []string
+	}{
+		{name: "valid path", req: setfacl("/srv/a", entry("user", "alice", "rwx", "add"))},
+		{name: "missing path", req: setfacl("", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "relative path", req: setfacl("srv/a", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "trailing separator", req: setfacl("/srv/a/", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "repeated separators", req: setfacl("/srv//a", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "dot segment", req: setfacl("/srv/./a", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "dot-dot segment", req: setfacl("/srv/x/../a", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{name: "path not on any server", req: setfacl("/srv/missing", entry("user", "alice", "rwx", "add")), wantFields: []string{"targetPath"}},
+		{
+			name:       "source path outside copyacl",
+			req:        &types.ScheduleTransactionRequest{Operation: types.OperationSetACL, TargetPath: "/srv/a", SourcePath: "/srv/b", Entries: []types.ACLEntry{entry("user", "alice", "rwx", "add")}},
+			wantFields: []string{"sourcePath"},
+		},
+		{
+			name:       "target paths outside copyacl",
+			req:        &types.ScheduleTransactionRequest{Operation: types.OperationGetACL, TargetPath: "/srv/a", TargetPaths: []string{"/srv/b"}},
+			wantFields: []string{"sourcePath"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := newTestValidator()
+			if got := errorFields(t, v.ValidateRequest(tt.req)); !equalFields(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	tests := []struct {
+		permissions string
+		valid       bool
+	}{
+		{permissions: "rwx", valid: true},
+		{permissions: "r--", valid: true},
+		{permissions: "---", valid: true},
+		{permissions: "r-X", valid: true},
+		{permissions: "-wX", valid: true},
+		{permissions: "", valid: false},
+		{permissions: "rw", valid: false},
+		{permissions: "rwxx", valid: false},
+		{permissions: "wrx", valid: false},
+		{permissions: "RWX", valid: false},
+		{permissions: "rwX ", valid: false},
+		{permissions: "7", valid: false},
+		{permissions: "r-x\n", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.permissions), func(t *testing.T) {
+			v, _ := newTestValidator()
+
+			err := v.ValidateRequest(setfacl("/srv/a", entry("user", "alice", tt.permissions, "modify")))
+			got := errorFields(t, err)
+			if tt.valid && got != nil {
+				t.Errorf("fields = %v, want valid", got)
+			}
+			if !tt.valid && !equalFields(got, []string{"entries[0].permissions"}) {
+				t.Errorf("fields = %v, want entries[0].permissions", got)
+			}
+
+			/* the mask of a "set" mask mode follows the same rule */
+			masked := entry("user", "alice", "rwx", "add")
+			masked.MaskMode = types.MaskSet
+			masked.MaskPermissions = tt.permissions
+			got = errorFields(t, v.ValidateRequest(setfacl("/srv/a", masked)))
+			if tt.valid != (got == nil) {
+				t.Errorf("mask fields = %v, want valid %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateEntries(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	grant := func(e types.ACLEntry) types.ACLEntry {
+		e.ExpiresAt = &expiry
+		return e
+	}
+	isDefault := func(e types.ACLEntry) types.ACLEntry {
+		e.IsDefault = true
+		return e
+	}
+	withMask := func(e types.ACLEntry, mode types.MaskMode, permissions string) types.ACLEntry {
+		e.MaskMode = mode
+		e.MaskPermissions = permissions
+		return e
+	}
+
+	tests := []struct {
+		name       string
+		entries    []types.ACLEntry
+		wantFields []string
+	}{
+		{name: "remove-all alone", entries: []types.ACLEntry{entry("", "", "", "remove-all")}},
+		{name: "remove-default alone", entries: []types.ACLEntry{entry("", "", "", "remove-default")}},
+		{name: "remove-all with an entity type", entries: []types.ACLEntry{entry("user", "", "", "remove-all")}, wantFields: []string{"entries[0]"}},
+		{name: "remove-all with an entity", entries: []types.ACLEntry{entry("", "alice", "", "remove-all")}, wantFields: []string{"entries[0]"}},
+		{name: "remove-default with permissions", entries: []types.ACLEntry{entry("", "", "rwx", "remove-default")}, wantFields: []string{"entries[0]"}},
+		{name: "remove-default with a mask mode", entries: []types.ACLEntry{withMask(entry("", "", "", "remove-default"), types.MaskPreserve, "")}, wantFields: []string{"entries[0].maskMode"}},
+		{name: "remove-all before other entries", entries: []types.ACLEntry{entry("", "", "", "remove-all"), entry("user", "alice", "rwx", "add")}},
+		{name: "unknown action", entries: []types.ACLEntry{entry("user", "alice", "rwx", "grant")}, wantFields: []string{"entries[0].action"}},
+		{name: "unknown entity type", entries: []types.ACLEntry{entry("owner", "", "rwx", "modify")}, wantFields: []string{"entries[0].entityType"}},
+		{name: "named mask", entries: []types.ACLEntry{entry("mask", "alice", "rwx", "modify")}, wantFields: []string{"entries[0].entity"}},
+		{name: "remove named user", entries: []types.ACLEntry{entry("user", "alice", "", "remove")}},
+		{name: "remove owner", entries: []types.ACLEntry{entry("user", "", "", "remove")}, wantFields: []string{"entries[0].entity"}},
+		{name: "remove other", entries: []types.ACLEntry{entry("other", "", "", "remove")}, wantFields: []string{"entries[0].entity"}},
+		{name: "remove with invalid permissions", entries: []types.ACLEntry{entry("user", "alice", "rw", "remove")}, wantFields: []string{"entries[0].permissions"}},
+		{name: "unknown user", entries: []types.ACLEntry{entry("user", "mallory", "rwx", "add")}, wantFields: []string{"entries[0].entity"}},
+		{name: "unknown group", entries: []types.ACLEntry{entry("group", "wheel", "rwx", "add")}, wantFields: []string{"entries[0].entity"}},
+		{name: "mask permissions without set mode", entries: []types.ACLEntry{withMask(entry("user", "alice", "rwx", "add"), types.MaskPreserve, "r--")}, wantFields: []string{"entries[0].maskPermissions"}},
+		{name: "unknown mask mode", entries: []types.ACLEntry{withMask(entry("user", "alice", "rwx", "add"), "keep", "")}, wantFields: []string{"entries[0].maskMode"}},
+		{
+			name:    "complete set",
+			entries: []types.ACLEntry{entry("user", "", "rwx", "set"), entry("group", "", "r-x", "set"), entry("other", "", "---", "set")},
+		},
+		{
+			name:       "incomplete access set",
+			entries:    []types.ACLEntry{entry("user", "", "rwx", "set"), entry("group", "", "r-x", "set")},
+			wantFields: []string{"entries"},
+		},
+		{
+			name:       "incomplete default set",
+			entries:    []types.ACLEntry{isDefault(entry("user", "", "rwx", "set")), isDefault(entry("other", "", "---", "set"))},
+			wantFields: []string{"entries"},
+		},
+		{name: "grant on a named user", entries: []types.ACLEntry{grant(entry("user", "alice", "r--", "add"))}},
+		{name: "grant on the owner", entries: []types.ACLEntry{grant(entry("user", "", "r--", "modify"))}, wantFields: []string{"entries[0].expiresAt"}},
+		{name: "grant with remove", entries: []types.ACLEntry{grant(entry("group", "staff", "", "remove"))}, wantFields: []string{"entries[0].expiresAt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := newTestValidator()
+			if got := errorFields(t, v.ValidateRequest(setfacl("/srv/a", tt.entries...))); !equalFields(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateCopyACL(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *types.ScheduleTransactionRequest
+		wantFields []string
+	}{
+		{name: "single target", req: copyacl("/srv/src", "/srv/a")},
+		{name: "several targets", req: copyacl("/srv/src", "/srv/a", "/srv/b")},
+		{name: "missing source", req: copyacl("", "/srv/a"), wantFields: []string{"sourcePath"}},
+		{name: "relative source", req: copyacl("srv/src", "/srv/a"), wantFields: []string{"sourcePath"}},
+		{name: "source not on any server", req: copyacl("/srv/missing", "/srv/a"), wantFields: []string{"sourcePath"}},
+		{name: "missing target", req: copyacl("/srv/src", ""), wantFields: []string{"targetPath"}},
+		{name: "invalid target among several", req: copyacl("/srv/src", "/srv/a", "srv/b", "/srv/missing"), wantFields: []string{"targetPaths[1]", "targetPaths[2]"}},
+		{
+			name:       "entries aren't copied",
+			req:        &types.ScheduleTransactionRequest{Operation: types.OperationCopyACL, SourcePath: "/srv/src", TargetPath: "/srv/a", Entries: []types.ACLEntry{entry("user", "alice", "rwx", "add")}},
+			wantFields: []string{"entries"},
+		},
+		{
+			name:       "recursive copy",
+			req:        &types.ScheduleTransactionRequest{Operation: types.OperationCopyACL, SourcePath: "/srv/src", TargetPath: "/srv/a", Recursive: true},
+			wantFields: []string{"recursive"},
+		},
+		{
+			name:       "target paths win over target path",
+			req:        &types.ScheduleTransactionRequest{Operation: types.OperationCopyACL, SourcePath: "/srv/src", TargetPath: "relative", TargetPaths: []string{"/srv/a"}},
+			wantFields: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := newTestValidator()
+			if got := errorFields(t, v.ValidateRequest(tt.req)); !equalFields(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateLookups(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *types.ScheduleTransactionRequest
+		wantErr     error
+		wantLookups int
+	}{
+		{
+			name:        "each entity is looked up once",
+			req:         setfacl("/srv/a", entry("user", "alice", "rwx", "add"), entry("user", "alice", "r--", "add"), entry("group", "staff", "r--", "add")),
+			wantLookups: 2,
+		},
+		{
+			name:        "owner entries aren't looked up",
+			req:         setfacl("/srv/a", entry("user", "", "rwx", "modify"), entry("group", "", "r--", "modify")),
+			wantLookups: 0,
+		},
+		{
+			name:        "directory failure isn't a field error",
+			req:         setfacl("/srv/a", entry("user", "flaky", "rwx", "add")),
+			wantErr:     errDirectoryDown,
+			wantLookups: 1,
+		},
+		{
+			name:    "server failure isn't a field error",
+			req:     setfacl("/srv/broken", entry("user", "", "rwx", "modify")),
+			wantErr: errors.New("daemon unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, directory := newTestValidator()
+			err := v.ValidateRequest(tt.req)
+
+			var fieldErrs FieldErrors
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("err = %v, want valid", err)
+			case tt.wantErr != nil && (err == nil || errors.As(err, &fieldErrs)):
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			case errors.Is(tt.wantErr, errDirectoryDown) && !errors.Is(err, errDirectoryDown):
+				t.Errorf("err = %v, want it to wrap %v", err, errDirectoryDown)
+			}
+
+			if directory.lookups != tt.wantLookups {
+				t.Errorf("%d lookups, want %d", directory.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
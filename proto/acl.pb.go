@@ -22,14 +22,16 @@ const (
 )
 
 type ACLEntry struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	EntityType    string                 `protobuf:"bytes,1,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // "user", "group", "mask", "other"
-	Entity        string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`                           // e.g., "alice", "", etc.
-	Permissions   string                 `protobuf:"bytes,3,opt,name=permissions,proto3" json:"permissions,omitempty"`                 // e.g., "rw-"
-	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`                           // "add", "modify", "remove"
-	IsDefault     bool                   `protobuf:"varint,5,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EntityType      string                 `protobuf:"bytes,1,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // "user", "group", "mask", "other"
+	Entity          string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`                           // e.g., "alice", "", etc.
+	Permissions     string                 `protobuf:"bytes,3,opt,name=permissions,proto3" json:"permissions,omitempty"`                 // e.g., "rw-", "r-X"
+	Action          string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`                           // "add", "modify", "remove", "set", "remove-all", "remove-default"
+	IsDefault       bool                   `protobuf:"varint,5,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	MaskMode        string                 `protobuf:"bytes,6,opt,name=mask_mode,json=maskMode,proto3" json:"mask_mode,omitempty"`                      // "recalculate" (default), "preserve", "set"
+	MaskPermissions string                 `protobuf:"bytes,7,opt,name=mask_permissions,json=maskPermissions,proto3" json:"mask_permissions,omitempty"` // mask to set with mask_mode "set"
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ACLEntry) Reset() {
@@ -97,11 +99,27 @@ func (x *ACLEntry) GetIsDefault() bool {
 	return false
 }
 
+func (x *ACLEntry) GetMaskMode() string {
+	if x != nil {
+		return x.MaskMode
+	}
+	return ""
+}
+
+func (x *ACLEntry) GetMaskPermissions() string {
+	if x != nil {
+		return x.MaskPermissions
+	}
+	return ""
+}
+
 type ApplyACLRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TransactionID string                 `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
 	TargetPath    string                 `protobuf:"bytes,2,opt,name=target_path,json=targetPath,proto3" json:"target_path,omitempty"`
-	Entry         *ACLEntry              `protobuf:"bytes,3,opt,name=entry,proto3" json:"entry,omitempty"`
+	Entry         *ACLEntry              `protobuf:"bytes,3,opt,name=entry,proto3" json:"entry,omitempty"`          // single entry (kept for older daemons, set when entries has exactly one)
+	Entries       []*ACLEntry            `protobuf:"bytes,4,rep,name=entries,proto3" json:"entries,omitempty"`      // entries applied together on target_path
+	Recursive     bool                   `protobuf:"varint,5,opt,name=recursive,proto3" json:"recursive,omitempty"` // apply to every file under target_path (setfacl -R)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -157,17 +175,138 @@ func (x *ApplyACLRequest) GetEntry() *ACLEntry {
 	return nil
 }
 
+func (x *ApplyACLRequest) GetEntries() []*ACLEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ApplyACLRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+type ACLEntryResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ACLEntryResult) Reset() {
+	*x = ACLEntryResult{}
+	mi := &file_proto_acl_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ACLEntryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ACLEntryResult) ProtoMessage() {}
+
+func (x *ACLEntryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ACLEntryResult.ProtoReflect.Descriptor instead.
+func (*ACLEntryResult) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ACLEntryResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ACLEntryResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type PathFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathFailure) Reset() {
+	*x = PathFailure{}
+	mi := &file_proto_acl_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathFailure) ProtoMessage() {}
+
+func (x *PathFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathFailure.ProtoReflect.Descriptor instead.
+func (*PathFailure) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PathFailure) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PathFailure) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type ApplyACLResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Results       []*ACLEntryResult      `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`   // one result per entry, in request order
+	Failures      []*PathFailure         `protobuf:"bytes,4,rep,name=failures,proto3" json:"failures,omitempty"` // paths that failed during a recursive walk
+	Warnings      []string               `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"` // entries narrowed down by the mask
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ApplyACLResponse) Reset() {
 	*x = ApplyACLResponse{}
-	mi := &file_proto_acl_proto_msgTypes[2]
+	mi := &file_proto_acl_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -179,7 +318,7 @@ func (x *ApplyACLResponse) String() string {
 func (*ApplyACLResponse) ProtoMessage() {}
 
 func (x *ApplyACLResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_acl_proto_msgTypes[2]
+	mi := &file_proto_acl_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -192,7 +331,7 @@ func (x *ApplyACLResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ApplyACLResponse.ProtoReflect.Descriptor instead.
 func (*ApplyACLResponse) Descriptor() ([]byte, []int) {
-	return file_proto_acl_proto_rawDescGZIP(), []int{2}
+	return file_proto_acl_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ApplyACLResponse) GetSuccess() bool {
@@ -209,11 +348,348 @@ func (x *ApplyACLResponse) GetMessage() string {
 	return ""
 }
 
+func (x *ApplyACLResponse) GetResults() []*ACLEntryResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ApplyACLResponse) GetFailures() []*PathFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+func (x *ApplyACLResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type ACLRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityType    string                 `protobuf:"bytes,1,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"` // "user", "group", "mask", "other"
+	Entity        string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`                           // e.g., "alice", "" for owner/owning group
+	Permissions   string                 `protobuf:"bytes,3,opt,name=permissions,proto3" json:"permissions,omitempty"`                 // e.g., "r-x"
+	IsDefault     bool                   `protobuf:"varint,4,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ACLRule) Reset() {
+	*x = ACLRule{}
+	mi := &file_proto_acl_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ACLRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ACLRule) ProtoMessage() {}
+
+func (x *ACLRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ACLRule.ProtoReflect.Descriptor instead.
+func (*ACLRule) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ACLRule) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *ACLRule) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *ACLRule) GetPermissions() string {
+	if x != nil {
+		return x.Permissions
+	}
+	return ""
+}
+
+func (x *ACLRule) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+type GetACLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionID string                 `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	TargetPath    string                 `protobuf:"bytes,2,opt,name=target_path,json=targetPath,proto3" json:"target_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetACLRequest) Reset() {
+	*x = GetACLRequest{}
+	mi := &file_proto_acl_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetACLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetACLRequest) ProtoMessage() {}
+
+func (x *GetACLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetACLRequest.ProtoReflect.Descriptor instead.
+func (*GetACLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetACLRequest) GetTransactionID() string {
+	if x != nil {
+		return x.TransactionID
+	}
+	return ""
+}
+
+func (x *GetACLRequest) GetTargetPath() string {
+	if x != nil {
+		return x.TargetPath
+	}
+	return ""
+}
+
+type GetACLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Entries       []*ACLRule             `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`           // access ACL followed by default ACL
+	IsDir         bool                   `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"` // target_path is a directory
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetACLResponse) Reset() {
+	*x = GetACLResponse{}
+	mi := &file_proto_acl_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetACLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetACLResponse) ProtoMessage() {}
+
+func (x *GetACLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetACLResponse.ProtoReflect.Descriptor instead.
+func (*GetACLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetACLResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetACLResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetACLResponse) GetEntries() []*ACLRule {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetACLResponse) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+type RestoreACLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionID string                 `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	TargetPath    string                 `protobuf:"bytes,2,opt,name=target_path,json=targetPath,proto3" json:"target_path,omitempty"`
+	Acl           []*ACLRule             `protobuf:"bytes,3,rep,name=acl,proto3" json:"acl,omitempty"`           // access and default ACL to write (default is skipped on files)
+	Expected      []*ACLRule             `protobuf:"bytes,4,rep,name=expected,proto3" json:"expected,omitempty"` // refuse unless target_path holds exactly this ACL (empty: no check)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreACLRequest) Reset() {
+	*x = RestoreACLRequest{}
+	mi := &file_proto_acl_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreACLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreACLRequest) ProtoMessage() {}
+
+func (x *RestoreACLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreACLRequest.ProtoReflect.Descriptor instead.
+func (*RestoreACLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RestoreACLRequest) GetTransactionID() string {
+	if x != nil {
+		return x.TransactionID
+	}
+	return ""
+}
+
+func (x *RestoreACLRequest) GetTargetPath() string {
+	if x != nil {
+		return x.TargetPath
+	}
+	return ""
+}
+
+func (x *RestoreACLRequest) GetAcl() []*ACLRule {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *RestoreACLRequest) GetExpected() []*ACLRule {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+type RestoreACLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Conflict      bool                   `protobuf:"varint,3,opt,name=conflict,proto3" json:"conflict,omitempty"` // ACL differed from expected, nothing was written
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreACLResponse) Reset() {
+	*x = RestoreACLResponse{}
+	mi := &file_proto_acl_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreACLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreACLResponse) ProtoMessage() {}
+
+func (x *RestoreACLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_acl_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreACLResponse.ProtoReflect.Descriptor instead.
+func (*RestoreACLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_acl_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RestoreACLResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RestoreACLResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RestoreACLResponse) GetConflict() bool {
+	if x != nil {
+		return x.Conflict
+	}
+	return false
+}
+
 var File_proto_acl_proto protoreflect.FileDescriptor
 
 const file_proto_acl_proto_rawDesc = "" +
 	"\n" +
-	"\x0fproto/acl.proto\x12\x03acl\"\x9c\x01\n" +
+	"\x0fproto/acl.proto\x12\x03acl\"\xe4\x01\n" +
 	"\bACLEntry\x12\x1f\n" +
 	"\ventity_type\x18\x01 \x01(\tR\n" +
 	"entityType\x12\x16\n" +
@@ -221,18 +697,60 @@ const file_proto_acl_proto_rawDesc = "" +
 	"\vpermissions\x18\x03 \x01(\tR\vpermissions\x12\x16\n" +
 	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1d\n" +
 	"\n" +
-	"is_default\x18\x05 \x01(\bR\tisDefault\"}\n" +
+	"is_default\x18\x05 \x01(\bR\tisDefault\x12\x1b\n" +
+	"\tmask_mode\x18\x06 \x01(\tR\bmaskMode\x12)\n" +
+	"\x10mask_permissions\x18\a \x01(\tR\x0fmaskPermissions\"\xc4\x01\n" +
 	"\x0fApplyACLRequest\x12$\n" +
 	"\rtransactionID\x18\x01 \x01(\tR\rtransactionID\x12\x1f\n" +
 	"\vtarget_path\x18\x02 \x01(\tR\n" +
 	"targetPath\x12#\n" +
-	"\x05entry\x18\x03 \x01(\v2\r.acl.ACLEntryR\x05entry\"F\n" +
+	"\x05entry\x18\x03 \x01(\v2\r.acl.ACLEntryR\x05entry\x12'\n" +
+	"\aentries\x18\x04 \x03(\v2\r.acl.ACLEntryR\aentries\x12\x1c\n" +
+	"\trecursive\x18\x05 \x01(\bR\trecursive\"D\n" +
+	"\x0eACLEntryResult\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\";\n" +
+	"\vPathFailure\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xbf\x01\n" +
 	"\x10ApplyACLResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2J\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12-\n" +
+	"\aresults\x18\x03 \x03(\v2\x13.acl.ACLEntryResultR\aresults\x12,\n" +
+	"\bfailures\x18\x04 \x03(\v2\x10.acl.PathFailureR\bfailures\x12\x1a\n" +
+	"\bwarnings\x18\x05 \x03(\tR\bwarnings\"\x83\x01\n" +
+	"\aACLRule\x12\x1f\n" +
+	"\ventity_type\x18\x01 \x01(\tR\n" +
+	"entityType\x12\x16\n" +
+	"\x06entity\x18\x02 \x01(\tR\x06entity\x12 \n" +
+	"\vpermissions\x18\x03 \x01(\tR\vpermissions\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\x04 \x01(\bR\tisDefault\"V\n" +
+	"\rGetACLRequest\x12$\n" +
+	"\rtransactionID\x18\x01 \x01(\tR\rtransactionID\x12\x1f\n" +
+	"\vtarget_path\x18\x02 \x01(\tR\n" +
+	"targetPath\"\x83\x01\n" +
+	"\x0eGetACLResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
+	"\aentries\x18\x03 \x03(\v2\f.acl.ACLRuleR\aentries\x12\x15\n" +
+	"\x06is_dir\x18\x04 \x01(\bR\x05isDir\"\xa4\x01\n" +
+	"\x11RestoreACLRequest\x12$\n" +
+	"\rtransactionID\x18\x01 \x01(\tR\rtransactionID\x12\x1f\n" +
+	"\vtarget_path\x18\x02 \x01(\tR\n" +
+	"targetPath\x12\x1e\n" +
+	"\x03acl\x18\x03 \x03(\v2\f.acl.ACLRuleR\x03acl\x12(\n" +
+	"\bexpected\x18\x04 \x03(\v2\f.acl.ACLRuleR\bexpected\"d\n" +
+	"\x12RestoreACLResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
+	"\bconflict\x18\x03 \x01(\bR\bconflict2\xbc\x01\n" +
 	"\n" +
 	"ACLService\x12<\n" +
-	"\rApplyACLEntry\x12\x14.acl.ApplyACLRequest\x1a\x15.acl.ApplyACLResponseBYZWgithub.com/PythonHacker24/linux-acl-management-aclapi/internal/grpcserver/protos;protosb\x06proto3"
+	"\rApplyACLEntry\x12\x14.acl.ApplyACLRequest\x1a\x15.acl.ApplyACLResponse\x121\n" +
+	"\x06GetACL\x12\x12.acl.GetACLRequest\x1a\x13.acl.GetACLResponse\x12=\n" +
+	"\n" +
+	"RestoreACL\x12\x16.acl.RestoreACLRequest\x1a\x17.acl.RestoreACLResponseBYZWgithub.com/PythonHacker24/linux-acl-management-aclapi/internal/grpcserver/protos;protosb\x06proto3"
 
 var (
 	file_proto_acl_proto_rawDescOnce sync.Once
@@ -246,21 +764,38 @@ func file_proto_acl_proto_rawDescGZIP() []byte {
 	return file_proto_acl_proto_rawDescData
 }
 
-var file_proto_acl_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_acl_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_proto_acl_proto_goTypes = []any{
-	(*ACLEntry)(nil),         // 0: acl.ACLEntry
-	(*ApplyACLRequest)(nil),  // 1: acl.ApplyACLRequest
-	(*ApplyACLResponse)(nil), // 2: acl.ApplyACLResponse
+	(*ACLEntry)(nil),           // 0: acl.ACLEntry
+	(*ApplyACLRequest)(nil),    // 1: acl.ApplyACLRequest
+	(*ACLEntryResult)(nil),     // 2: acl.ACLEntryResult
+	(*PathFailure)(nil),        // 3: acl.PathFailure
+	(*ApplyACLResponse)(nil),   // 4: acl.ApplyACLResponse
+	(*ACLRule)(nil),            // 5: acl.ACLRule
+	(*GetACLRequest)(nil),      // 6: acl.GetACLRequest
+	(*GetACLResponse)(nil),     // 7: acl.GetACLResponse
+	(*RestoreACLRequest)(nil),  // 8: acl.RestoreACLRequest
+	(*RestoreACLResponse)(nil), // 9: acl.RestoreACLResponse
 }
 var file_proto_acl_proto_depIdxs = []int32{
-	0, // 0: acl.ApplyACLRequest.entry:type_name -> acl.ACLEntry
-	1, // 1: acl.ACLService.ApplyACLEntry:input_type -> acl.ApplyACLRequest
-	2, // 2: acl.ACLService.ApplyACLEntry:output_type -> acl.ApplyACLResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: acl.ApplyACLRequest.entry:type_name -> acl.ACLEntry
+	0,  // 1: acl.ApplyACLRequest.entries:type_name -> acl.ACLEntry
+	2,  // 2: acl.ApplyACLResponse.results:type_name -> acl.ACLEntryResult
+	3,  // 3: acl.ApplyACLResponse.failures:type_name -> acl.PathFailure
+	5,  // 4: acl.GetACLResponse.entries:type_name -> acl.ACLRule
+	5,  // 5: acl.RestoreACLRequest.acl:type_name -> acl.ACLRule
+	5,  // 6: acl.RestoreACLRequest.expected:type_name -> acl.ACLRule
+	1,  // 7: acl.ACLService.ApplyACLEntry:input_type -> acl.ApplyACLRequest
+	6,  // 8: acl.ACLService.GetACL:input_type -> acl.GetACLRequest
+	8,  // 9: acl.ACLService.RestoreACL:input_type -> acl.RestoreACLRequest
+	4,  // 10: acl.ACLService.ApplyACLEntry:output_type -> acl.ApplyACLResponse
+	7,  // 11: acl.ACLService.GetACL:output_type -> acl.GetACLResponse
+	9,  // 12: acl.ACLService.RestoreACL:output_type -> acl.RestoreACLResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_proto_acl_proto_init() }
@@ -274,7 +809,7 @@ func file_proto_acl_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_acl_proto_rawDesc), len(file_proto_acl_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,6 +20,8 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	ACLService_ApplyACLEntry_FullMethodName = "/acl.ACLService/ApplyACLEntry"
+	ACLService_GetACL_FullMethodName        = "/acl.ACLService/GetACL"
+	ACLService_RestoreACL_FullMethodName    = "/acl.ACLService/RestoreACL"
 )
 
 // ACLServiceClient is the client API for ACLService service.
@@ -27,6 +29,8 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ACLServiceClient interface {
 	ApplyACLEntry(ctx context.Context, in *ApplyACLRequest, opts ...grpc.CallOption) (*ApplyACLResponse, error)
+	GetACL(ctx context.Context, in *GetACLRequest, opts ...grpc.CallOption) (*GetACLResponse, error)
+	RestoreACL(ctx context.Context, in *RestoreACLRequest, opts ...grpc.CallOption) (*RestoreACLResponse, error)
 }
 
 type aCLServiceClient struct {
@@ -47,11 +51,33 @@ func (c *aCLServiceClient) ApplyACLEntry(ctx context.Context, in *ApplyACLReques
 	return out, nil
 }
 
+func (c *aCLServiceClient) GetACL(ctx context.Context, in *GetACLRequest, opts ...grpc.CallOption) (*GetACLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetACLResponse)
+	err := c.cc.Invoke(ctx, ACLService_GetACL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aCLServiceClient) RestoreACL(ctx context.Context, in *RestoreACLRequest, opts ...grpc.CallOption) (*RestoreACLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreACLResponse)
+	err := c.cc.Invoke(ctx, ACLService_RestoreACL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ACLServiceServer is the server API for ACLService service.
 // All implementations must embed UnimplementedACLServiceServer
 // for forward compatibility.
 type ACLServiceServer interface {
 	ApplyACLEntry(context.Context, *ApplyACLRequest) (*ApplyACLResponse, error)
+	GetACL(context.Context, *GetACLRequest) (*GetACLResponse, error)
+	RestoreACL(context.Context, *RestoreACLRequest) (*RestoreACLResponse, error)
 	mustEmbedUnimplementedACLServiceServer()
 }
 
@@ -65,6 +91,12 @@ type UnimplementedACLServiceServer struct{}
 func (UnimplementedACLServiceServer) ApplyACLEntry(context.Context, *ApplyACLRequest) (*ApplyACLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ApplyACLEntry not implemented")
 }
+func (UnimplementedACLServiceServer) GetACL(context.Context, *GetACLRequest) (*GetACLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetACL not implemented")
+}
+func (UnimplementedACLServiceServer) RestoreACL(context.Context, *RestoreACLRequest) (*RestoreACLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreACL not implemented")
+}
 func (UnimplementedACLServiceServer) mustEmbedUnimplementedACLServiceServer() {}
 func (UnimplementedACLServiceServer) testEmbeddedByValue()                    {}
 
@@ -104,6 +136,42 @@ func _ACLService_ApplyACLEntry_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ACLService_GetACL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetACLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ACLServiceServer).GetACL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ACLService_GetACL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ACLServiceServer).GetACL(ctx, req.(*GetACLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ACLService_RestoreACL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreACLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ACLServiceServer).RestoreACL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ACLService_RestoreACL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ACLServiceServer).RestoreACL(ctx, req.(*RestoreACLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ACLService_ServiceDesc is the grpc.ServiceDesc for ACLService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +183,14 @@ var ACLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ApplyACLEntry",
 			Handler:    _ACLService_ApplyACLEntry_Handler,
 		},
+		{
+			MethodName: "GetACL",
+			Handler:    _ACLService_GetACL_Handler,
+		},
+		{
+			MethodName: "RestoreACL",
+			Handler:    _ACLService_RestoreACL_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/acl.proto",